@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude"
+)
+
+var (
+	recordDir string
+	replayDir string
+)
+
+// newCassette creates the cassette selected by --record or --replay, or nil
+// when neither is set. Recordings are masked like the displayed output.
+func newCassette() (*claude.Cassette, error) {
+	switch {
+	case recordDir != "" && replayDir != "":
+		return nil, errors.New("--record and --replay cannot be used together")
+	case recordDir != "":
+		redactor, err := newRedactor()
+		if err != nil {
+			return nil, err
+		}
+		cassette, err := claude.NewRecorder(recordDir)
+		if err != nil {
+			return nil, err
+		}
+		cassette.Redact = redactor.Redact
+		return cassette, nil
+	case replayDir != "":
+		return claude.NewReplayer(replayDir)
+	default:
+		return nil, nil
+	}
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Save each claude invocation's args, cwd and raw output, with secrets masked, to this directory")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Replay claude invocations recorded with --record instead of running the CLI")
+}
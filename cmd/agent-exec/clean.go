@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/commands/clean"
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanDryRun      bool
+	cleanOlderThan   time.Duration
+	cleanKeepWinners bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Delete leftover candidate branches and worktrees",
+	Long: `Delete leftover candidate branches and worktrees created by agent-exec.
+
+Candidates are recognized through the lineage notes evolve writes under
+refs/notes/agent-exec, not by branch name, so branches you created yourself
+are never touched. A branch checked out in the main working tree or in a
+worktree you created yourself is skipped; only worktrees agent-exec created
+under .agent-exec/worktrees are removed.
+
+Isolated runs record their worktree and branch in .agent-exec/runs/<run-id>
+when they start. Those worktrees are removed too, along with the branch of an
+isolated loop run; an agent-exec/ branch that no run recorded is left alone.
+--keep-winners keeps the run branches, and --older-than applies to runs by the
+time they started.
+
+Example:
+  agent-exec clean --dry-run
+  agent-exec clean --older-than 72h --keep-winners`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := clean.Options{
+			DryRun:      cleanDryRun,
+			OlderThan:   cleanOlderThan,
+			KeepWinners: cleanKeepWinners,
+		}
+
+		gitClient := git.NewClient(events.NewNullEmitter())
+		if err := clean.Run(gitClient, opts, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanCmd)
+
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "List what would be deleted without deleting anything")
+	cleanCmd.Flags().DurationVar(&cleanOlderThan, "older-than", 0, "Only delete candidates created at least this long ago (e.g., 24h)")
+	cleanCmd.Flags().BoolVar(&cleanKeepWinners, "keep-winners", false, "Keep the final winning branch of each run and agent-exec/<run-id> run branches")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude"
+	"github.com/LinHanLab/agent-exec/pkg/display"
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+// Output formats selected by --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var (
+	outputFormat   string
+	displayConfig  string
+	maxLines       int
+	maxChars       int
+	hideFields     []string
+	toolLineLimits map[string]int
+	alwaysShow     []string
+	redactPatterns []string
+)
+
+// filterConfig builds the display filter settings from --display-config and the filter flags
+func filterConfig() (display.FilterConfig, error) {
+	cfg := display.DefaultFilterConfig()
+	if displayConfig != "" {
+		var err error
+		if cfg, err = display.LoadFilterConfig(displayConfig); err != nil {
+			return cfg, err
+		}
+	}
+
+	if maxLines > 0 {
+		cfg.MaxLines = maxLines
+	}
+	if maxChars > 0 {
+		cfg.MaxChars = maxChars
+	}
+	for _, spec := range hideFields {
+		tool, field, ok := strings.Cut(spec, ".")
+		if !ok || tool == "" || field == "" {
+			return cfg, fmt.Errorf("invalid --hide-field %q: want Tool.field", spec)
+		}
+		if cfg.HideFields == nil {
+			cfg.HideFields = make(map[string][]string)
+		}
+		cfg.HideFields[tool] = append(cfg.HideFields[tool], field)
+	}
+	for tool, limit := range toolLineLimits {
+		if cfg.ToolLineLimits == nil {
+			cfg.ToolLineLimits = make(map[string]int)
+		}
+		cfg.ToolLineLimits[tool] = limit
+	}
+	cfg.AlwaysShow = append(cfg.AlwaysShow, alwaysShow...)
+	cfg.Redact = append(cfg.Redact, redactPatterns...)
+
+	return cfg, nil
+}
+
+// newFormatter creates the formatter selected by --output: NDJSON, or console
+// output configured by the display flags, with a status line tracking repo
+func newFormatter(verbose, diffs, statusLine bool, repo git.Repo) (display.Formatter, error) {
+	switch outputFormat {
+	case outputJSON:
+		return display.NewJSONFormatter(os.Stdout), nil
+	case outputText:
+	default:
+		return nil, fmt.Errorf("invalid --output %q: must be %s or %s", outputFormat, outputText, outputJSON)
+	}
+
+	opts, err := consoleOptions(verbose, diffs)
+	if err != nil {
+		return nil, err
+	}
+	console := display.NewConsoleFormatter(os.Stdout, verbose, opts...)
+	if statusLine {
+		return display.NewStatusLineFormatter(console, os.Stdout, true, repo), nil
+	}
+	return console, nil
+}
+
+// newRedactor creates a redactor for the built-in secret patterns and --redact
+func newRedactor() (*display.Redactor, error) {
+	cfg, err := filterConfig()
+	if err != nil {
+		return nil, err
+	}
+	return display.NewRedactor(cfg.Redact)
+}
+
+// consoleOptions configures console output with the display flags
+func consoleOptions(verbose, diffs bool) ([]display.ConsoleOption, error) {
+	cfg, err := filterConfig()
+	if err != nil {
+		return nil, err
+	}
+	filter := display.NewContentFilterWithConfig(verbose, cfg)
+
+	opts := []display.ConsoleOption{display.WithContentFilter(filter)}
+	if diffs {
+		opts = append(opts, display.WithDiffs())
+	}
+	return opts, nil
+}
+
+// newTUI creates the full-screen dashboard whose keys steer control
+func newTUI(verbose, diffs bool, control *claude.Control) (display.Formatter, error) {
+	if outputFormat != outputText {
+		return nil, fmt.Errorf("--tui cannot be combined with --output %s", outputFormat)
+	}
+	consoleOpts, err := consoleOptions(verbose, diffs)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []display.TUIOption{
+		display.WithController(control),
+		display.WithConsoleOptions(consoleOpts...),
+	}
+	if maxCost > 0 {
+		opts = append(opts, display.WithCostLimit(maxCost))
+	}
+	return display.NewTUIFormatter(os.Stdin, os.Stdout, verbose, opts...)
+}
+
+// newDisplay creates a display whose events pass through the redaction set by --redact
+func newDisplay(formatter display.Formatter, emitter events.Emitter) (*display.Display, error) {
+	redactor, err := newRedactor()
+	if err != nil {
+		return nil, err
+	}
+	return display.NewDisplay(formatter, emitter, display.WithRedactor(redactor)), nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, "Output format: text, or json for one JSON object per event (NDJSON)")
+	rootCmd.PersistentFlags().StringVar(&displayConfig, "display-config", "", "JSON file with console filter settings (hide_fields, max_lines, max_chars, tool_line_limits, always_show, redact)")
+	rootCmd.PersistentFlags().IntVar(&maxLines, "max-lines", 0, fmt.Sprintf("Lines shown per tool input or output unless verbose (default %d)", display.MaxCodeBlockLines))
+	rootCmd.PersistentFlags().IntVar(&maxChars, "max-chars", 0, fmt.Sprintf("Characters shown per tool input or output unless verbose (default %d)", display.MaxCodeBlockChars))
+	rootCmd.PersistentFlags().StringArrayVar(&hideFields, "hide-field", nil, "Hide a tool input field unless verbose, as Tool.field (repeatable)")
+	rootCmd.PersistentFlags().StringToIntVar(&toolLineLimits, "tool-lines", nil, "Lines shown for a tool's input and output, as Tool=N (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&alwaysShow, "always-show", nil, "Never hide or truncate this tool's input and output (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&redactPatterns, "redact", nil, "Mask matches of this regex in all output, run logs, recordings, commit messages and lineage notes, in addition to built-in secret patterns (repeatable)")
+}
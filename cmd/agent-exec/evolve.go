@@ -5,19 +5,34 @@ import (
 	"os"
 	"time"
 
+	"github.com/LinHanLab/agent-exec/pkg/claude"
 	"github.com/LinHanLab/agent-exec/pkg/commands/evolve"
 	"github.com/LinHanLab/agent-exec/pkg/display"
 	"github.com/LinHanLab/agent-exec/pkg/events"
 	"github.com/LinHanLab/agent-exec/pkg/git"
+	"github.com/LinHanLab/agent-exec/pkg/report"
+	"github.com/LinHanLab/agent-exec/pkg/stats"
 	"github.com/spf13/cobra"
 )
 
 var (
-	improvePrompt       string
+	improvePrompts      []string
+	improveFile         string
+	promptSchedule      string
 	comparePrompt       string
 	evolveIters         int
 	evolveSleep         time.Duration
 	compareErrorRetries int
+	crossover           bool
+	crossoverPrompt     string
+	summarizeCommits    bool
+	evolveStash         bool
+	evolveIsolate       bool
+	onExit              string
+	keepIncomplete      bool
+	finishMode          string
+	finishBranch        string
+	summaryMessage      bool
 
 	evolveSystemPrompt       string
 	evolveAppendSystemPrompt string
@@ -29,8 +44,10 @@ var (
 	compareAppendSystemPrompt string
 
 	evolveVerbose     bool
+	evolveDiffs       bool
 	debugKeepBranches bool
 	evolveStatusLine  bool
+	evolveTUI         bool
 )
 
 var evolveCmd = &cobra.Command{
@@ -42,22 +59,84 @@ Creates an initial implementation, then iteratively improves through rounds:
   1. Create a challenger branch from the current winner
   2. Run improvement prompt on the challenger
   3. AI compares both branches and eliminates the loser
-  4. Repeat with the winner
+  4. Optionally (--crossover), combine the winner and runner-up into a new
+     branch that challenges the winner
+  5. Repeat with the winner
+
+The repository must be on a branch with a clean working tree and no merge or
+rebase in progress. Use --stash to set uncommitted changes aside for the run
+(they are restored on the original branch, which is checked out at the end),
+or --isolate to run in a separate worktree under .agent-exec/worktrees and
+leave your checkout untouched.
+
+With --tui, the run is shown as a full-screen dashboard with the candidate
+leaderboard. Press p to pause after the current step (and again to resume), s
+to skip a sleep and q to stop after the current step.
 
 Example:
-  agent-exec evolve "implement a snake game" -n 3`,
+  agent-exec evolve "implement a snake game" -n 3
+  agent-exec evolve "implement a snake game" -n 3 --finish squash
+  agent-exec evolve "implement a snake game" -n 3 --finish squash --summary-message
+  agent-exec evolve "implement a snake game" -n 3 --isolate --finish pr-branch
+  agent-exec evolve "implement a snake game" -n 6 -i "add tests" -i "optimize performance" -i "polish UX"`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		prompt := args[0]
 
+		prompts := improvePrompts
+		if improveFile != "" {
+			filePrompts, err := evolve.LoadPromptSchedule(improveFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if cmd.Flags().Changed("improve") {
+				prompts = append(prompts, filePrompts...)
+			} else {
+				prompts = filePrompts
+			}
+		}
+
+		// Stashed changes are restored on the original branch, so return there
+		exitBranch := onExit
+		if evolveStash && !cmd.Flags().Changed("on-exit") {
+			exitBranch = evolve.OnExitOriginal
+		}
+
+		cassette, err := newCassette()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// Commit messages and lineage notes can be pushed, so they are masked like the output
+		redactor, err := newRedactor()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		cfg := evolve.EvolveConfig{
 			Prompt:              prompt,
-			ImprovePrompt:       improvePrompt,
+			ImprovePrompts:      prompts,
+			PromptSchedule:      promptSchedule,
 			ComparePrompt:       comparePrompt,
 			Iterations:          evolveIters,
 			Sleep:               evolveSleep,
 			CompareErrorRetries: compareErrorRetries,
 			DebugKeepBranches:   debugKeepBranches,
+			Crossover:           crossover,
+			CrossoverPrompt:     crossoverPrompt,
+			SummarizeCommits:    summarizeCommits,
+			Stash:               evolveStash,
+			Isolate:             evolveIsolate,
+			OnExit:              exitBranch,
+			KeepIncomplete:      keepIncomplete,
+			Finish:              finishMode,
+			FinishBranch:        finishBranch,
+			RunID:               events.NewRunID(),
+			Cassette:            cassette,
+			Budget:              newBudget(),
+			Redact:              redactor.Redact,
 
 			SystemPrompt:       evolveSystemPrompt,
 			AppendSystemPrompt: evolveAppendSystemPrompt,
@@ -71,43 +150,77 @@ Example:
 
 		// Create emitter and display
 		emitter := events.NewChannelEmitter(100)
-		baseFormatter := display.NewConsoleFormatter(os.Stdout, evolveVerbose)
 		gitClient := git.NewClient(emitter)
-
 		var formatter display.Formatter
-		if evolveStatusLine {
-			formatter = display.NewStatusLineFormatter(baseFormatter, os.Stdout, true, gitClient)
+		if evolveTUI {
+			cfg.Control = claude.NewControl()
+			formatter, err = newTUI(evolveVerbose, evolveDiffs, cfg.Control)
 		} else {
-			formatter = baseFormatter
+			formatter, err = newFormatter(evolveVerbose, evolveDiffs, evolveStatusLine, gitClient)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		formatter, closeLog, err := withRunLog(formatter, gitClient, cfg.RunID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		disp := display.NewDisplay(formatter, emitter)
+		disp, err := newDisplay(formatter, emitter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 		disp.Start()
 
-		err := evolve.Evolve(cfg, emitter)
+		// Record the run to build the squash commit message from its summary
+		var runEmitter events.Emitter = emitter
+		if summaryMessage {
+			recorder := report.NewRecorder(emitter)
+			cfg.SquashMessage = func() (string, error) {
+				return report.CommitMessage(report.Build(recorder.Events())), nil
+			}
+			runEmitter = recorder
+		}
+
+		// Tally tool usage for the summary printed when the run ends
+		collector := stats.NewCollector(runEmitter)
+
+		err = evolve.Evolve(cfg, collector)
+		collector.Summarize()
 
 		// Close emitter and wait for display to finish
 		emitter.Close()
 		disp.Wait()
+		closeLog()
 
-		if err != nil {
-			if err.Error() == "interrupted" {
-				os.Exit(130)
-			}
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		exitOnRunError(err)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(evolveCmd)
 
-	evolveCmd.Flags().StringVarP(&improvePrompt, "improve", "i", "improve the code quality and fix any issues", "Prompt for creating improved challenger implementations")
+	evolveCmd.Flags().StringArrayVarP(&improvePrompts, "improve", "i", []string{"improve the code quality and fix any issues"}, "Prompt for creating improved challenger implementations (repeat to build a per-round schedule)")
+	evolveCmd.Flags().StringVar(&improveFile, "improve-file", "", "File of improvement prompts, one per line (blank lines and # comments ignored)")
+	evolveCmd.Flags().StringVar(&promptSchedule, "schedule", evolve.ScheduleCycle, "How improvement prompts are chosen each round: cycle or random")
 	evolveCmd.Flags().StringVarP(&comparePrompt, "compare", "c", "compare these two implementations and determine which is worse", "Prompt for comparing and selecting worse implementation")
 	evolveCmd.Flags().IntVarP(&evolveIters, "iterations", "n", 3, "Number of evolution rounds to run")
 	evolveCmd.Flags().DurationVarP(&evolveSleep, "sleep", "s", 0, "Sleep duration between evolution rounds (e.g., 30s, 1m)")
 	evolveCmd.Flags().IntVar(&compareErrorRetries, "compare-error-retries", 3, "Retry attempts when comparison branch parsing fails")
+	evolveCmd.Flags().BoolVar(&crossover, "crossover", false, "Each round, combine the winner and runner-up into a crossover challenger")
+	evolveCmd.Flags().StringVar(&crossoverPrompt, "crossover-prompt", "combine the best ideas from both implementations", "Prompt for creating crossover challengers")
+
+	evolveCmd.Flags().BoolVar(&evolveStash, "stash", false, "Stash uncommitted changes before the run and restore them on the original branch afterwards (implies --on-exit original)")
+	evolveCmd.Flags().BoolVar(&evolveIsolate, "isolate", false, "Run in a temporary git worktree under .agent-exec/worktrees so your checkout is never touched")
+	evolveCmd.Flags().StringVar(&onExit, "on-exit", evolve.OnExitWinner, "Branch to check out when the run ends or fails: winner or original (original with --stash)")
+	evolveCmd.Flags().BoolVar(&keepIncomplete, "keep-incomplete", false, "Keep an unfinished challenger as <branch>-incomplete instead of deleting it")
+	evolveCmd.Flags().StringVar(&finishMode, "finish", "", "Integrate the winner when done: merge, squash, rebase (fast-forward) or pr-branch (rename for review)")
+	evolveCmd.Flags().StringVar(&finishBranch, "finish-branch", "", "Branch name for --finish pr-branch (default agent-exec/<run-id>)")
+	evolveCmd.Flags().BoolVar(&summaryMessage, "summary-message", false, "Use the Markdown run summary as the squash commit message (requires --finish squash)")
+	evolveCmd.Flags().BoolVar(&summarizeCommits, "summarize-commits", false, "Ask Claude for a one-line summary of each candidate's changes for its commit message")
 
 	evolveCmd.Flags().StringVar(&evolveSystemPrompt, "system-prompt", "", "Replace entire system prompt for initial prompt")
 	evolveCmd.Flags().StringVar(&evolveAppendSystemPrompt, "append-system-prompt", "", "Append to default system prompt for initial prompt")
@@ -119,6 +232,8 @@ func init() {
 	evolveCmd.Flags().StringVar(&compareAppendSystemPrompt, "append-compare-system-prompt", "", "Append to default system prompt for comparison steps")
 
 	evolveCmd.Flags().BoolVarP(&evolveVerbose, "verbose", "v", false, "Show verbose output including all Claude events")
+	evolveCmd.Flags().BoolVar(&evolveDiffs, "diff", false, "Show file edits as colored diffs and file writes as summaries")
 	evolveCmd.Flags().BoolVar(&debugKeepBranches, "debug-keep-branches", false, "Keep all branches for debugging instead of deleting losers")
 	evolveCmd.Flags().BoolVar(&evolveStatusLine, "status-line", true, "Show updating status line")
+	evolveCmd.Flags().BoolVar(&evolveTUI, "tui", false, "Show a full-screen dashboard with keys to pause, skip sleep and abort")
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude"
+	"github.com/LinHanLab/agent-exec/pkg/commands/evolve"
+	"github.com/LinHanLab/agent-exec/pkg/commands/loop"
+)
+
+// Exit codes of the loop and evolve commands
+const (
+	exitFailure     = 1   // The run failed
+	exitPartial     = 2   // Some loop iterations or evolve challengers failed
+	exitBudget      = 4   // The --max-cost budget ran out
+	exitInterrupted = 130 // Interrupted by SIGINT or SIGTERM
+)
+
+var maxCost float64
+
+// newBudget creates the budget set by --max-cost, or nil when unlimited
+func newBudget() *claude.Budget {
+	if maxCost <= 0 {
+		return nil
+	}
+	return claude.NewBudget(maxCost)
+}
+
+// exitCode maps a run's error to the command's exit code
+func exitCode(err error) int {
+	var failedErr *loop.FailedIterationsError
+	var challengersErr *evolve.FailedChallengersError
+	switch {
+	case errors.Is(err, claude.ErrInterrupted):
+		return exitInterrupted
+	case errors.Is(err, claude.ErrBudgetExhausted):
+		return exitBudget
+	case errors.As(err, &failedErr) && failedErr.Failed < failedErr.Total:
+		return exitPartial
+	case errors.As(err, &challengersErr):
+		return exitPartial
+	default:
+		return exitFailure
+	}
+}
+
+// exitOnRunError reports a run's error and exits with its exit code
+func exitOnRunError(err error) {
+	if err == nil {
+		return
+	}
+	code := exitCode(err)
+	if code != exitInterrupted {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	os.Exit(code)
+}
+
+func init() {
+	rootCmd.PersistentFlags().Float64Var(&maxCost, "max-cost", 0, "Stop once Claude has cost this many USD in total (0 = unlimited)")
+}
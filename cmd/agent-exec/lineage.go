@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/LinHanLab/agent-exec/pkg/commands/lineage"
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+var lineageCmd = &cobra.Command{
+	Use:   "lineage [run-id]",
+	Short: "Print the family tree of an evolve run",
+	Long: `Print the family tree of an evolve run.
+
+Evolve records lineage metadata for every candidate commit as git notes under
+refs/notes/agent-exec: parent candidate, round, prompt, judge verdict, cost and
+fitness (comparisons survived). Without a run ID, the most recent run is shown.
+
+Example:
+  agent-exec lineage
+  agent-exec lineage 20250102-150405-a3f9c2`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runID := ""
+		if len(args) == 1 {
+			runID = args[0]
+		}
+
+		gitClient := git.NewClient(events.NewNullEmitter())
+		records, err := gitClient.ReadLineage()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		runID, selected, err := lineage.SelectRun(records, runID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := lineage.Render(os.Stdout, runID, selected); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lineageCmd)
+}
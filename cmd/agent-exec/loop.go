@@ -10,6 +10,7 @@ import (
 	"github.com/LinHanLab/agent-exec/pkg/display"
 	"github.com/LinHanLab/agent-exec/pkg/events"
 	"github.com/LinHanLab/agent-exec/pkg/git"
+	"github.com/LinHanLab/agent-exec/pkg/stats"
 	"github.com/spf13/cobra"
 )
 
@@ -19,7 +20,12 @@ var (
 	systemPrompt       string
 	appendSystemPrompt string
 	verbose            bool
+	diffs              bool
 	statusLine         bool
+	isolate            bool
+	continueSession    bool
+	resetSessionEvery  int
+	loopTUI            bool
 )
 
 var loopCmd = &cobra.Command{
@@ -30,50 +36,101 @@ var loopCmd = &cobra.Command{
 Each iteration runs Claude Code with the given prompt. Use -n to set the
 number of iterations and -s to add sleep between runs.
 
+With --isolate, Claude works in a new worktree under .agent-exec/worktrees on
+an agent-exec/<run-id> branch, so your checkout is never touched. The worktree
+is kept afterwards for review; agent-exec clean removes it with its branch.
+
+With --continue-session, each iteration resumes the previous iteration's
+Claude session so context carries over; --reset-session-every N starts a fresh
+session every N iterations to keep that context bounded.
+
+With --tui, the run is shown as a full-screen dashboard. Press p to pause
+after the current step (and again to resume), s to skip a sleep and q to stop
+after the current step.
+
 Example:
-  agent-exec loop "improve code quality" -n 5 -s 30s`,
+  agent-exec loop "improve code quality" -n 5 -s 30s
+  agent-exec loop "refine the design doc" -n 12 --continue-session --reset-session-every 4
+  agent-exec loop "improve code quality" -n 5 --isolate
+  agent-exec loop "improve code quality" -n 5 -s 1m --tui`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		prompt := args[0]
 
+		session := loop.SessionOptions{Continue: continueSession, ResetEvery: resetSessionEvery}
+		if err := session.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		cassette, err := newCassette()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 		opts := &claude.PromptOptions{
 			SystemPrompt:       systemPrompt,
 			AppendSystemPrompt: appendSystemPrompt,
+			Cassette:           cassette,
+			Budget:             newBudget(),
 		}
 
+		runID := events.NewRunID()
+
 		// Create emitter and display
 		emitter := events.NewChannelEmitter(100)
-		baseFormatter := display.NewConsoleFormatter(os.Stdout, verbose)
 		gitClient := git.NewClient(emitter)
-
 		var formatter display.Formatter
-		if statusLine {
-			formatter = display.NewStatusLineFormatter(baseFormatter, os.Stdout, true, gitClient)
+		if loopTUI {
+			opts.Control = claude.NewControl()
+			formatter, err = newTUI(verbose, diffs, opts.Control)
 		} else {
-			formatter = baseFormatter
+			formatter, err = newFormatter(verbose, diffs, statusLine, gitClient)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		formatter, closeLog, err := withRunLog(formatter, gitClient, runID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		disp := display.NewDisplay(formatter, emitter)
+		disp, err := newDisplay(formatter, emitter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 		disp.Start()
 
-		var err error
-		if iterations == 1 {
-			_, err = claude.RunPrompt(prompt, opts, emitter)
-		} else {
-			err = loop.RunPromptLoop(iterations, sleep, prompt, opts, emitter)
+		// Tally tool usage for the summary printed when the run ends
+		collector := stats.NewCollector(emitter)
+
+		if isolate {
+			var worktree git.Repo
+			worktree, err = gitClient.AddWorktree(runID, "HEAD", git.RunBranchPrefix+runID)
+			if err == nil {
+				opts.Dir = worktree.Dir()
+			}
 		}
 
+		if err == nil {
+			if iterations == 1 {
+				_, err = claude.RunPrompt(prompt, opts, collector)
+			} else {
+				err = loop.RunPromptLoop(iterations, sleep, prompt, opts, session, collector)
+			}
+		}
+		collector.Summarize()
+
 		// Close emitter and wait for display to finish
 		emitter.Close()
 		disp.Wait()
+		closeLog()
 
-		if err != nil {
-			if err.Error() == "interrupted" {
-				os.Exit(130)
-			}
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
+		exitOnRunError(err)
 	},
 }
 
@@ -85,5 +142,10 @@ func init() {
 	loopCmd.Flags().StringVar(&systemPrompt, "system-prompt", "", "Replace entire system prompt sent to Claude")
 	loopCmd.Flags().StringVar(&appendSystemPrompt, "append-system-prompt", "", "Append additional instructions to default system prompt")
 	loopCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output including all Claude events")
+	loopCmd.Flags().BoolVar(&diffs, "diff", false, "Show file edits as colored diffs and file writes as summaries")
 	loopCmd.Flags().BoolVar(&statusLine, "status-line", true, "Show updating status line")
+	loopCmd.Flags().BoolVar(&loopTUI, "tui", false, "Show a full-screen dashboard with keys to pause, skip sleep and abort")
+	loopCmd.Flags().BoolVar(&continueSession, "continue-session", false, "Resume the previous iteration's Claude session instead of starting a new one")
+	loopCmd.Flags().IntVar(&resetSessionEvery, "reset-session-every", 0, "With --continue-session, start a fresh session every N iterations (0 = never)")
+	loopCmd.Flags().BoolVar(&isolate, "isolate", false, "Run in a new git worktree under .agent-exec/worktrees instead of the current checkout")
 }
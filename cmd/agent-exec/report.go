@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/LinHanLab/agent-exec/pkg/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportHTML    bool
+	reportOut     string
+	reportVerbose bool
+	reportDiffs   bool
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report <run-dir>",
+	Short: "Show or export the event log of a finished run",
+	Long: `Show or export the event log of a finished run.
+
+Loop and evolve runs inside a git repository save every event to
+.agent-exec/runs/<run-id>/events.ndjson, with a Markdown summary suitable for
+a pull request description in summary.md. Without flags the run is replayed
+to the terminal. With --html a single self-contained HTML page is written,
+showing the rounds, each candidate's diff, the judge's verdicts, tool calls
+with collapsible output, durations and costs.
+
+Example:
+  agent-exec report .agent-exec/runs/20250102-150405-a3f9c2
+  agent-exec report .agent-exec/runs/20250102-150405-a3f9c2 --html -o evolve.html`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDir := args[0]
+
+		evs, err := report.Load(runDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !reportHTML {
+			formatter, err := newFormatter(reportVerbose, reportDiffs, false, nil)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			for _, event := range evs {
+				if err := formatter.Format(event); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			return
+		}
+
+		out := reportOut
+		if out == "" {
+			out = filepath.Join(runDir, "report.html")
+		}
+		file, err := os.Create(out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		err = report.WriteHTML(file, report.Build(evs))
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(out)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().BoolVar(&reportHTML, "html", false, "Write an HTML report instead of replaying the run")
+	reportCmd.Flags().StringVarP(&reportOut, "out", "o", "", "HTML report file (default <run-dir>/report.html)")
+	reportCmd.Flags().BoolVarP(&reportVerbose, "verbose", "v", false, "Show verbose output including all Claude events")
+	reportCmd.Flags().BoolVar(&reportDiffs, "diff", false, "Show file edits as colored diffs and file writes as summaries")
+}
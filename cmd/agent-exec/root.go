@@ -10,6 +10,23 @@ var rootCmd = &cobra.Command{
 Runs Claude Code CLI in headless mode for automation with human-readable terminal output.
 
 Commands:
+  clean     Delete leftover candidate branches and worktrees
   evolve    Tournament-style code evolution using git branches
-  loop      Run the same prompt multiple times`,
+  lineage   Print the family tree of an evolve run
+  loop      Run the same prompt multiple times
+  report    Show or export the event log of a finished run
+
+Environment:
+  AGENT_EXEC_CLAUDE   Path of the claude executable to run (default "claude")
+
+Output:
+  --output json writes one JSON object per event to stdout, with the keys
+  "type", "timestamp" and "data". Durations are integer nanoseconds.
+
+Exit codes (loop and evolve):
+  0    Success
+  1    Failure
+  2    Some loop iterations or evolve challengers failed, but the run finished
+  4    The --max-cost budget ran out
+  130  Interrupted, or stopped with q in the --tui dashboard`,
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/LinHanLab/agent-exec/pkg/display"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+	"github.com/LinHanLab/agent-exec/pkg/report"
+)
+
+var runLog bool
+
+// withRunLog adds a formatter saving every event to .agent-exec/runs/<runID>
+// at the repository root. Outside a repository no log is saved, so runs never
+// leave a state directory in an arbitrary working directory. The returned
+// function closes the log, writes the Markdown summary next to it and reports
+// where they were saved.
+func withRunLog(formatter display.Formatter, gitClient *git.Client, runID string) (display.Formatter, func(), error) {
+	if !runLog {
+		return formatter, func() {}, nil
+	}
+
+	dir, err := gitClient.StatePath(git.RunsDir, runID)
+	if err != nil {
+		return formatter, func() {}, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	file, err := os.Create(filepath.Join(dir, report.EventsFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create run log: %w", err)
+	}
+
+	closeLog := func() {
+		if err := file.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to save run log: %v\n", err)
+			return
+		}
+		if err := writeSummary(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write run summary: %v\n", err)
+		}
+		fmt.Fprintf(os.Stderr, "Run log: %s\n", dir)
+	}
+	return display.NewMultiFormatter(formatter, display.NewJSONFormatter(file)), closeLog, nil
+}
+
+// writeSummary writes the Markdown summary of the run saved in dir
+func writeSummary(dir string) error {
+	evs, err := report.Load(dir)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(dir, report.SummaryFile))
+	if err != nil {
+		return err
+	}
+	err = report.WriteMarkdown(file, report.Build(evs))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&runLog, "run-log", true, "Inside a git repository, save all events to .agent-exec/runs/<run-id>/events.ndjson for \"agent-exec report\", with a Markdown summary in summary.md")
+}
@@ -1,3 +1,11 @@
 module agent-exec
 
-go 1.25.3
+go 1.26.0
+
+require (
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/sys v0.48.0
+	golang.org/x/term v0.46.0
+)
+
+require github.com/spf13/pflag v1.0.9 // indirect
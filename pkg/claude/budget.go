@@ -0,0 +1,52 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrBudgetExhausted is returned by RunPrompt once a Budget's spending limit is reached
+var ErrBudgetExhausted = errors.New("cost budget exhausted")
+
+// Budget caps the total cost reported by the claude invocations sharing it
+type Budget struct {
+	MaxUSD float64
+
+	mu    sync.Mutex
+	spent float64
+}
+
+// NewBudget creates a budget allowing maxUSD of spending
+func NewBudget(maxUSD float64) *Budget {
+	return &Budget{MaxUSD: maxUSD}
+}
+
+// Spent returns the cost recorded so far
+func (b *Budget) Spent() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.spent
+}
+
+// check fails once the budget is used up; a nil budget never fails
+func (b *Budget) check() error {
+	if b == nil {
+		return nil
+	}
+	spent := b.Spent()
+	if spent >= b.MaxUSD {
+		return fmt.Errorf("%w: spent $%.4f of $%.4f", ErrBudgetExhausted, spent, b.MaxUSD)
+	}
+	return nil
+}
+
+// add records the cost of an invocation
+func (b *Budget) add(costUSD float64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent += costUSD
+}
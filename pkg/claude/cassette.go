@@ -0,0 +1,267 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// Cassette modes
+const (
+	CassetteRecord = "record"
+	CassetteReplay = "replay"
+)
+
+// Cassette saves each claude invocation's args, working directory and raw
+// stream-json output to a directory, or replays those recordings in order
+// instead of running the CLI
+type Cassette struct {
+	Dir    string
+	Mode   string
+	Redact func(string) string // Masks secrets in recordings (nil = record as is)
+
+	mu   sync.Mutex
+	next int // Number of the next invocation, counting from 1
+}
+
+// cassetteEntry is the metadata saved for each invocation
+type cassetteEntry struct {
+	Prompt   string   `json:"prompt"`
+	Args     []string `json:"args"`
+	Cwd      string   `json:"cwd"`
+	ExitCode int      `json:"exit_code"`
+	Stream   string   `json:"stream"` // File holding the raw stdout, relative to the cassette
+}
+
+// candidateBranchPattern matches branch names from git.RandomBranchName. They
+// differ on every run, so replay maps recorded names to the current ones.
+var candidateBranchPattern = regexp.MustCompile(`impl-[0-9a-f]{6}`)
+
+// NewRecorder creates a cassette recording into dir, creating it if needed
+func NewRecorder(dir string) (*Cassette, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return &Cassette{Dir: dir, Mode: CassetteRecord, next: 1}, nil
+}
+
+// NewReplayer creates a cassette replaying the recordings in dir
+func NewReplayer(dir string) (*Cassette, error) {
+	if _, err := os.Stat(entryPath(dir, 1)); err != nil {
+		return nil, fmt.Errorf("no recordings found in cassette %s: %w", dir, err)
+	}
+	return &Cassette{Dir: dir, Mode: CassetteReplay, next: 1}, nil
+}
+
+// entryPath returns the metadata file of the n-th invocation
+func entryPath(dir string, n int) string {
+	return filepath.Join(dir, fmt.Sprintf("%04d.json", n))
+}
+
+// claim reserves the number of the next invocation
+func (c *Cassette) claim() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.next
+	c.next++
+	return n
+}
+
+// recording is an invocation being written to a cassette
+type recording struct {
+	path   string
+	entry  cassetteEntry
+	stream *os.File
+	out    *redactWriter // Writes to stream; nil without redaction
+}
+
+// startRecording opens the stream file of the next invocation
+func (c *Cassette) startRecording(prompt string, args []string, cwd string) (*recording, error) {
+	n := c.claim()
+	streamName := fmt.Sprintf("%04d.stream.ndjson", n)
+	stream, err := os.Create(filepath.Join(c.Dir, streamName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette recording: %w", err)
+	}
+	rec := &recording{
+		path:   entryPath(c.Dir, n),
+		entry:  cassetteEntry{Prompt: prompt, Args: args, Cwd: cwd, Stream: streamName},
+		stream: stream,
+	}
+	if c.Redact != nil {
+		rec.entry.Prompt = c.Redact(prompt)
+		rec.entry.Cwd = c.Redact(cwd)
+		rec.entry.Args = make([]string, len(args))
+		for i, arg := range args {
+			rec.entry.Args[i] = c.Redact(arg)
+		}
+		rec.out = &redactWriter{w: stream, redact: c.Redact}
+	}
+	return rec, nil
+}
+
+// teeStream copies the CLI output into the recording while it is parsed
+func (r *recording) teeStream(stdout io.Reader) io.Reader {
+	if r.out != nil {
+		return io.TeeReader(stdout, r.out)
+	}
+	return io.TeeReader(stdout, r.stream)
+}
+
+// finish closes the stream and writes the invocation's metadata
+func (r *recording) finish(exitCode int) error {
+	if r.out != nil {
+		if err := r.out.flush(); err != nil {
+			_ = r.stream.Close()
+			return fmt.Errorf("failed to save cassette recording: %w", err)
+		}
+	}
+	if err := r.stream.Close(); err != nil {
+		return fmt.Errorf("failed to save cassette recording: %w", err)
+	}
+	r.entry.ExitCode = exitCode
+	data, err := json.MarshalIndent(r.entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save cassette recording: %w", err)
+	}
+	return nil
+}
+
+// redactWriter masks secrets line by line, so a secret split across reads is
+// still matched
+type redactWriter struct {
+	w      io.Writer
+	redact func(string) string
+	buf    []byte
+}
+
+// Write passes every complete line through redactLine and holds back the rest
+func (rw *redactWriter) Write(p []byte) (int, error) {
+	rw.buf = append(rw.buf, p...)
+	for {
+		i := bytes.IndexByte(rw.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if _, err := io.WriteString(rw.w, redactLine(string(rw.buf[:i]), rw.redact)+"\n"); err != nil {
+			return 0, err
+		}
+		rw.buf = rw.buf[i+1:]
+	}
+}
+
+// flush writes a final line without a trailing newline
+func (rw *redactWriter) flush() error {
+	if len(rw.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(rw.w, redactLine(string(rw.buf), rw.redact))
+	rw.buf = nil
+	return err
+}
+
+// redactLine masks the string values of a stream-json line and encodes it
+// again, so masking cannot break the JSON escaping. Lines that are not JSON
+// are masked as plain text.
+func redactLine(line string, redact func(string) string) string {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return redact(line)
+	}
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redactJSON(value, redact)); err != nil {
+		return redact(line)
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// redactJSON masks every string in a decoded JSON value
+func redactJSON(value interface{}, redact func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return redact(v)
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = redactJSON(item, redact)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item, redact)
+		}
+	}
+	return value
+}
+
+// replay parses the next recorded invocation as if the CLI had produced it
+func (c *Cassette) replay(prompt string, emitter events.Emitter) (Result, error) {
+	n := c.claim()
+	data, err := os.ReadFile(entryPath(c.Dir, n))
+	if err != nil {
+		return Result{}, fmt.Errorf("cassette %s has no recording %d: %w", c.Dir, n, err)
+	}
+	var entry cassetteEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Result{}, fmt.Errorf("invalid cassette recording %d: %w", n, err)
+	}
+
+	stream, err := os.ReadFile(filepath.Join(c.Dir, entry.Stream))
+	if err != nil {
+		return Result{}, fmt.Errorf("cassette %s has no stream for recording %d: %w", c.Dir, n, err)
+	}
+
+	replaced := remapBranches(string(stream), entry.Prompt, prompt)
+	result, err := ParseStreamJSON(strings.NewReader(replaced), emitter)
+	if err != nil {
+		return result, err
+	}
+
+	if entry.ExitCode != 0 {
+		return Result{}, fmt.Errorf("claude CLI failed: exit status %d (replayed)", entry.ExitCode)
+	}
+	return result, nil
+}
+
+// remapBranches replaces candidate branch names from the recorded prompt with
+// the names in the same positions of the current prompt
+func remapBranches(text, recordedPrompt, prompt string) string {
+	recorded := candidateBranchPattern.FindAllString(recordedPrompt, -1)
+	current := candidateBranchPattern.FindAllString(prompt, -1)
+	if len(recorded) == 0 || len(recorded) != len(current) {
+		return text
+	}
+
+	var pairs []string
+	for i, name := range recorded {
+		pairs = append(pairs, name, current[i])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// exitCode extracts the process exit code from a Wait error
+func exitCode(err error) int {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,221 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude/claudetest"
+	"github.com/LinHanLab/agent-exec/pkg/display"
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{
+		{Match: "compare", Verdict: claudetest.VerdictSecond},
+		{Match: "broken", ExitCode: 3, Text: "partial"},
+		{Text: "first answer", CostUSD: 0.5},
+	}})
+	dir := filepath.Join(t.TempDir(), "cassette")
+
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	opts := &PromptOptions{Cassette: recorder}
+	recorded, err := RunPrompt("hello", opts, events.NewNullEmitter())
+	if err != nil {
+		t.Fatalf("recording RunPrompt failed: %v", err)
+	}
+	if _, err := RunPrompt("broken run", opts, events.NewNullEmitter()); err == nil {
+		t.Fatal("recording RunPrompt succeeded; want exit status error")
+	}
+	if _, err := RunPrompt("compare\n- impl-aaaaaa\n- impl-bbbbbb", opts, events.NewNullEmitter()); err != nil {
+		t.Fatalf("recording RunPrompt failed: %v", err)
+	}
+
+	// Replaying must not start the CLI
+	t.Setenv(BinaryEnv, filepath.Join(t.TempDir(), "missing-claude"))
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatalf("NewReplayer failed: %v", err)
+	}
+	opts = &PromptOptions{Cassette: replayer}
+
+	replayed, err := RunPrompt("hello", opts, events.NewNullEmitter())
+	if err != nil {
+		t.Fatalf("replayed RunPrompt failed: %v", err)
+	}
+	if replayed.Text != recorded.Text || replayed.CostUSD != recorded.CostUSD {
+		t.Errorf("got replayed %+v; want recorded %+v", replayed, recorded)
+	}
+
+	if _, err := RunPrompt("broken run", opts, events.NewNullEmitter()); err == nil {
+		t.Error("replayed RunPrompt succeeded; want the recorded exit status")
+	}
+
+	verdict, err := RunPrompt("compare\n- impl-cccccc\n- impl-dddddd", opts, events.NewNullEmitter())
+	if err != nil {
+		t.Fatalf("replayed RunPrompt failed: %v", err)
+	}
+	if verdict.Text != "impl-dddddd" {
+		t.Errorf("got verdict %q; want recorded branch mapped to impl-dddddd", verdict.Text)
+	}
+
+	if _, err := RunPrompt("one too many", opts, events.NewNullEmitter()); err == nil {
+		t.Error("RunPrompt past the end of the cassette succeeded; want error")
+	}
+}
+
+func TestCassette_FailedStartLeavesNoRecording(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cassette")
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	opts := &PromptOptions{Cassette: recorder}
+
+	t.Setenv(BinaryEnv, filepath.Join(t.TempDir(), "missing-claude"))
+	if _, err := RunPrompt("hello", opts, events.NewNullEmitter()); err == nil {
+		t.Fatal("RunPrompt succeeded; want error starting a missing CLI")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("got cassette files %v after a failed start; want none", entries)
+	}
+
+	// The next invocation is still recorded as the first
+	claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{{Text: "answer"}}})
+	if _, err := RunPrompt("hello", opts, events.NewNullEmitter()); err != nil {
+		t.Fatalf("RunPrompt failed: %v", err)
+	}
+	if _, err := os.Stat(entryPath(dir, 1)); err != nil {
+		t.Errorf("first recording missing: %v", err)
+	}
+}
+
+func TestCassette_RecordRedacts(t *testing.T) {
+	claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{{Text: "the key is hunter2-key"}}})
+	dir := filepath.Join(t.TempDir(), "cassette")
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	recorder.Redact = func(text string) string {
+		return strings.ReplaceAll(text, "hunter2-key", "[masked]")
+	}
+
+	result, err := RunPrompt("use hunter2-key", &PromptOptions{Cassette: recorder}, events.NewNullEmitter())
+	if err != nil {
+		t.Fatalf("RunPrompt failed: %v", err)
+	}
+	if result.Text != "the key is hunter2-key" {
+		t.Errorf("got result %q; want the unmasked text while running", result.Text)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("got cassette files %v (%v); want a recording and its stream", entries, err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "hunter2-key") || !strings.Contains(string(data), "[masked]") {
+			t.Errorf("%s was not masked:\n%s", entry.Name(), data)
+		}
+	}
+}
+
+func TestCassette_RedactedRecordingReplays(t *testing.T) {
+	command := `echo "API_KEY=abcdefghijk" > .env`
+	claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{{Text: command}}})
+	dir := filepath.Join(t.TempDir(), "cassette")
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	redactor, err := display.NewRedactor(nil)
+	if err != nil {
+		t.Fatalf("NewRedactor failed: %v", err)
+	}
+	recorder.Redact = redactor.Redact
+	if _, err := RunPrompt("write the env file", &PromptOptions{Cassette: recorder}, events.NewNullEmitter()); err != nil {
+		t.Fatalf("recording RunPrompt failed: %v", err)
+	}
+
+	t.Setenv(BinaryEnv, filepath.Join(t.TempDir(), "missing-claude"))
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatalf("NewReplayer failed: %v", err)
+	}
+	replayed, err := RunPrompt("write the env file", &PromptOptions{Cassette: replayer}, events.NewNullEmitter())
+	if err != nil {
+		t.Fatalf("replaying the redacted recording failed: %v", err)
+	}
+	if want := `echo "API_KEY=` + display.RedactedText + `" > .env`; replayed.Text != want {
+		t.Errorf("got replayed text %q; want %q", replayed.Text, want)
+	}
+}
+
+func TestRedactWriter(t *testing.T) {
+	var out strings.Builder
+	w := &redactWriter{w: &out, redact: func(text string) string {
+		return strings.ReplaceAll(text, "secret", "******")
+	}}
+
+	// The secret is split across writes; it is only masked once its line is complete
+	for _, chunk := range []string{"a sec", "ret\nlast sec", "ret"} {
+		if _, err := w.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if want := "a ******\nlast ******"; out.String() != want {
+		t.Errorf("got %q; want %q", out.String(), want)
+	}
+}
+
+func TestRemapBranches(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		recorded string
+		current  string
+		want     string
+	}{
+		{
+			name:     "maps by position",
+			text:     "delete impl-aaaaaa",
+			recorded: "- impl-aaaaaa\n- impl-bbbbbb",
+			current:  "- impl-111111\n- impl-222222",
+			want:     "delete impl-111111",
+		},
+		{
+			name:     "no branches",
+			text:     "done",
+			recorded: "write tests",
+			current:  "write tests",
+			want:     "done",
+		},
+		{
+			name:     "count mismatch leaves text alone",
+			text:     "impl-aaaaaa",
+			recorded: "impl-aaaaaa",
+			current:  "impl-111111 impl-222222",
+			want:     "impl-aaaaaa",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := remapBranches(tt.text, tt.recorded, tt.current); got != tt.want {
+				t.Errorf("remapBranches() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
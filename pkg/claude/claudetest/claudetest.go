@@ -0,0 +1,317 @@
+// Package claudetest provides a scripted fake of the claude CLI for hermetic
+// end-to-end tests. The test binary re-executes itself as the fake: Install
+// points claude.RunPrompt at os.Args[0] and Main, called from TestMain, takes
+// over when the process was started that way.
+package claudetest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	// scriptEnv holds the script path; when set, the process acts as the fake CLI
+	scriptEnv = "AGENT_EXEC_FAKE_CLAUDE_SCRIPT"
+	// binaryEnv mirrors claude.BinaryEnv; claudetest does not import claude so
+	// the claude package can use it in its own tests
+	binaryEnv = "AGENT_EXEC_CLAUDE"
+)
+
+// Verdicts a Step can give to a comparison prompt
+const (
+	VerdictFirst  = "first"  // Name the first listed branch as the loser
+	VerdictSecond = "second" // Name the second listed branch as the loser
+)
+
+// Script lists how the fake CLI responds to successive invocations
+type Script struct {
+	Steps []Step `json:"steps"`
+}
+
+// Step is one scripted response. The first step whose Match and Call both
+// apply to an invocation is used; an invocation matching no step fails.
+type Step struct {
+	Match      string            `json:"match,omitempty"`      // Substring the prompt must contain (empty = any)
+	Call       int               `json:"call,omitempty"`       // Only the n-th invocation, counting from 1 (0 = any)
+	Files      map[string]string `json:"files,omitempty"`      // Files written relative to the working directory, reported as Write calls
+	Text       string            `json:"text,omitempty"`       // Final result text
+	Verdict    string            `json:"verdict,omitempty"`    // Answer a comparison prompt with the first or second branch
+	Transcript []string          `json:"transcript,omitempty"` // Raw stream-json lines replacing the generated ones
+	CostUSD    float64           `json:"cost_usd,omitempty"`
+	ErrorType  string            `json:"error_type,omitempty"` // Result subtype for an is_error result, e.g. "error_max_turns"
+	ExitCode   int               `json:"exit_code,omitempty"`
+}
+
+// Call records one invocation of the fake CLI
+type Call struct {
+	Prompt string   `json:"prompt"`
+	Dir    string   `json:"dir"`
+	Args   []string `json:"args"`
+}
+
+// Fake is an installed fake CLI
+type Fake struct {
+	scriptPath string
+}
+
+// Install makes claude.RunPrompt run the current test binary as a fake CLI
+// following script for the rest of the test. The test package's TestMain must
+// call Main.
+func Install(t testing.TB, script Script) *Fake {
+	t.Helper()
+
+	data, err := json.Marshal(script)
+	if err != nil {
+		t.Fatalf("failed to encode fake claude script: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "script.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("failed to write fake claude script: %v", err)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("failed to locate test binary: %v", err)
+	}
+	t.Setenv(binaryEnv, executable)
+	t.Setenv(scriptEnv, path)
+
+	return &Fake{scriptPath: path}
+}
+
+// Calls returns the invocations made so far, in order
+func (f *Fake) Calls(t testing.TB) []Call {
+	t.Helper()
+
+	file, err := os.Open(callsPath(f.scriptPath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("failed to read fake claude calls: %v", err)
+	}
+	defer file.Close()
+
+	var calls []Call
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var call Call
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			t.Fatalf("failed to decode fake claude call: %v", err)
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// Main acts as the fake CLI and exits when the process was started by a test
+// that called Install. Otherwise it returns immediately. Call it first in TestMain.
+func Main() {
+	scriptPath := os.Getenv(scriptEnv)
+	if scriptPath == "" {
+		return
+	}
+
+	code, err := run(scriptPath, os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fake claude: %v\n", err)
+		os.Exit(2)
+	}
+	os.Exit(code)
+}
+
+// callsPath is where invocations of the script at scriptPath are logged
+func callsPath(scriptPath string) string {
+	return scriptPath + ".calls"
+}
+
+// run handles one invocation and returns the exit code
+func run(scriptPath string, args []string) (int, error) {
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return 0, err
+	}
+	var script Script
+	if err := json.Unmarshal(data, &script); err != nil {
+		return 0, fmt.Errorf("invalid script: %w", err)
+	}
+
+	prompt := promptArg(args)
+	dir, err := os.Getwd()
+	if err != nil {
+		return 0, err
+	}
+
+	callNum, err := logCall(scriptPath, Call{Prompt: prompt, Dir: dir, Args: args})
+	if err != nil {
+		return 0, err
+	}
+
+	step, ok := findStep(script.Steps, prompt, callNum)
+	if !ok {
+		return 0, fmt.Errorf("no step matches call %d with prompt %q", callNum, prompt)
+	}
+
+	for name, content := range step.Files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return 0, err
+		}
+	}
+
+	text := step.Text
+	if step.Verdict != "" {
+		text, err = verdict(prompt, step.Verdict)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	lines := step.Transcript
+	if lines == nil {
+		lines, err = transcript(step, text, callNum)
+		if err != nil {
+			return 0, err
+		}
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	return step.ExitCode, nil
+}
+
+// promptArg returns the value of the -p flag
+func promptArg(args []string) string {
+	for i, arg := range args {
+		if arg == "-p" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// logCall appends the call to the calls log and returns its 1-based number
+func logCall(scriptPath string, call Call) (int, error) {
+	path := callsPath(scriptPath)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+
+	line, err := json.Marshal(call)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+
+	return strings.Count(string(existing), "\n") + 1, nil
+}
+
+// findStep returns the first step applying to the invocation
+func findStep(steps []Step, prompt string, callNum int) (Step, bool) {
+	for _, step := range steps {
+		if step.Call != 0 && step.Call != callNum {
+			continue
+		}
+		if !strings.Contains(prompt, step.Match) {
+			continue
+		}
+		return step, true
+	}
+	return Step{}, false
+}
+
+// verdict picks a branch from the "- <branch>" lines of a comparison prompt
+func verdict(prompt, which string) (string, error) {
+	var branches []string
+	for _, line := range strings.Split(prompt, "\n") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "- "); ok {
+			branches = append(branches, name)
+		}
+	}
+	if len(branches) < 2 {
+		return "", fmt.Errorf("comparison prompt lists %d branches; want 2", len(branches))
+	}
+
+	switch which {
+	case VerdictFirst:
+		return branches[len(branches)-2], nil
+	case VerdictSecond:
+		return branches[len(branches)-1], nil
+	default:
+		return "", fmt.Errorf("unknown verdict %q", which)
+	}
+}
+
+// transcript generates the stream-json lines for a step: one Write tool call
+// per file, an assistant message and the final result
+func transcript(step Step, text string, callNum int) ([]string, error) {
+	sessionID := fmt.Sprintf("fake-session-%d", callNum)
+	messages := []map[string]interface{}{
+		{"type": "system", "subtype": "init", "session_id": sessionID, "model": "fake-claude"},
+	}
+
+	for name, content := range step.Files {
+		messages = append(messages,
+			map[string]interface{}{
+				"type": "assistant",
+				"message": map[string]interface{}{"content": []interface{}{map[string]interface{}{
+					"type": "tool_use", "id": fmt.Sprintf("toolu_%s", name), "name": "Write",
+					"input": map[string]interface{}{"file_path": name, "content": content},
+				}}},
+			},
+			map[string]interface{}{
+				"type": "user",
+				"message": map[string]interface{}{"content": []interface{}{map[string]interface{}{
+					"type": "tool_result", "tool_use_id": fmt.Sprintf("toolu_%s", name),
+					"content": "File created successfully at: " + name,
+				}}},
+			})
+	}
+
+	if text != "" {
+		messages = append(messages, map[string]interface{}{
+			"type": "assistant",
+			"message": map[string]interface{}{"content": []interface{}{map[string]interface{}{
+				"type": "text", "text": text,
+			}}},
+		})
+	}
+
+	subtype := "success"
+	if step.ErrorType != "" {
+		subtype = step.ErrorType
+	}
+	messages = append(messages, map[string]interface{}{
+		"type": "result", "subtype": subtype, "is_error": step.ErrorType != "",
+		"result": text, "duration_ms": 10, "num_turns": len(step.Files) + 1,
+		"total_cost_usd": step.CostUSD, "session_id": sessionID,
+	})
+
+	lines := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, string(data))
+	}
+	return lines, nil
+}
@@ -0,0 +1,119 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrInterrupted is returned by a run stopped by SIGINT, SIGTERM or an aborted Control
+var ErrInterrupted = errors.New("interrupted")
+
+// ErrAborted is returned by RunPrompt once its Control was aborted. It wraps
+// ErrInterrupted so runs treat it like SIGINT.
+var ErrAborted = fmt.Errorf("aborted: %w", ErrInterrupted)
+
+// Control lets the user steer a run while it is going: pause before the next
+// claude invocation, cut a sleep short or stop gracefully
+type Control struct {
+	mu      sync.Mutex
+	paused  bool
+	resumed chan struct{} // Closed when the current pause ends
+	aborted bool
+	abort   chan struct{}
+	skip    chan struct{}
+}
+
+// NewControl creates a control for a run that is not paused
+func NewControl() *Control {
+	return &Control{
+		abort: make(chan struct{}),
+		skip:  make(chan struct{}, 1),
+	}
+}
+
+// TogglePause pauses the run before its next claude invocation, or resumes
+// it, and reports whether the run is now paused
+func (c *Control) TogglePause() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.paused = false
+		close(c.resumed)
+	} else {
+		c.paused = true
+		c.resumed = make(chan struct{})
+	}
+	return c.paused
+}
+
+// Paused reports whether the run is paused
+func (c *Control) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// SkipSleep ends the current sleep between iterations or rounds
+func (c *Control) SkipSleep() {
+	select {
+	case c.skip <- struct{}{}:
+	default:
+	}
+}
+
+// Abort stops the run once the current claude invocation finishes
+func (c *Control) Abort() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.aborted {
+		c.aborted = true
+		close(c.abort)
+	}
+}
+
+// Aborted returns a channel that is closed once the run is aborted; it is
+// never ready for a nil control
+func (c *Control) Aborted() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	return c.abort
+}
+
+// Skipped returns a channel receiving SkipSleep requests; it is never ready
+// for a nil control
+func (c *Control) Skipped() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	return c.skip
+}
+
+// wait blocks while the run is paused and fails once it is aborted; a nil
+// control never waits
+func (c *Control) wait() error {
+	if c == nil {
+		return nil
+	}
+	for {
+		c.mu.Lock()
+		paused, resumed := c.paused, c.resumed
+		c.mu.Unlock()
+
+		select {
+		case <-c.abort:
+			return ErrAborted
+		default:
+		}
+		if !paused {
+			return nil
+		}
+
+		select {
+		case <-resumed:
+		case <-c.abort:
+			return ErrAborted
+		}
+	}
+}
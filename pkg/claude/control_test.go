@@ -0,0 +1,59 @@
+package claude
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestControl_PauseAndResume(t *testing.T) {
+	control := NewControl()
+	if !control.TogglePause() || !control.Paused() {
+		t.Fatal("TogglePause() did not pause the run")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- control.wait() }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("wait() returned %v while paused; want it to block", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if control.TogglePause() {
+		t.Fatal("TogglePause() did not resume the run")
+	}
+	if err := <-done; err != nil {
+		t.Errorf("wait() = %v after resume; want nil", err)
+	}
+}
+
+func TestControl_Abort(t *testing.T) {
+	control := NewControl()
+	control.TogglePause()
+
+	done := make(chan error, 1)
+	go func() { done <- control.wait() }()
+
+	control.Abort()
+	control.Abort()
+	if err := <-done; !errors.Is(err, ErrAborted) || !errors.Is(err, ErrInterrupted) {
+		t.Errorf("wait() = %v after abort; want ErrAborted, an ErrInterrupted", err)
+	}
+	select {
+	case <-control.Aborted():
+	default:
+		t.Error("Aborted() channel not closed after Abort()")
+	}
+}
+
+func TestControl_Nil(t *testing.T) {
+	var control *Control
+	if err := control.wait(); err != nil {
+		t.Errorf("wait() on nil control = %v; want nil", err)
+	}
+	if control.Aborted() != nil || control.Skipped() != nil {
+		t.Error("nil control channels should be nil")
+	}
+}
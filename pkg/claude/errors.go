@@ -0,0 +1,68 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ResultErrorKind classifies an error result reported by the claude CLI
+type ResultErrorKind string
+
+// Kinds of error results
+const (
+	ErrorMaxTurns  ResultErrorKind = "max_turns"       // The run hit its turn limit (subtype error_max_turns)
+	ErrorExecution ResultErrorKind = "execution_error" // The run failed while executing (subtype error_during_execution)
+	ErrorAPI       ResultErrorKind = "api_error"       // The result is flagged is_error, e.g. an API failure
+)
+
+// ResultError is returned when the final result message reports a failure,
+// even if the claude process exits successfully
+type ResultError struct {
+	Kind     ResultErrorKind
+	Subtype  string // Raw result subtype
+	Message  string // Result text, if any
+	NumTurns int
+}
+
+func (e *ResultError) Error() string {
+	msg := fmt.Sprintf("claude reported %s", strings.ReplaceAll(string(e.Kind), "_", " "))
+	if e.Kind == ErrorMaxTurns && e.NumTurns > 0 {
+		msg += fmt.Sprintf(" after %d turns", e.NumTurns)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// resultError classifies a result message, returning nil for a successful one
+func resultError(msg ClaudeMessage) *ResultError {
+	var kind ResultErrorKind
+	switch {
+	case msg.Subtype == "error_max_turns":
+		kind = ErrorMaxTurns
+	case strings.HasPrefix(msg.Subtype, "error"):
+		kind = ErrorExecution
+	case msg.IsError:
+		kind = ErrorAPI
+	default:
+		return nil
+	}
+
+	return &ResultError{
+		Kind:     kind,
+		Subtype:  msg.Subtype,
+		Message:  strings.TrimSpace(msg.Result),
+		NumTurns: msg.NumTurns,
+	}
+}
+
+// FailureReason returns the kind of a ResultError in err's chain, or "" for other errors
+func FailureReason(err error) string {
+	var resultErr *ResultError
+	if errors.As(err, &resultErr) {
+		return string(resultErr.Kind)
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package claude
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+func TestParseStreamJSON_ErrorResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantKind ResultErrorKind
+		wantText string
+	}{
+		{
+			name:  "success",
+			input: `{"type":"result","subtype":"success","is_error":false,"result":"done"}`,
+		},
+		{
+			name:     "max turns",
+			input:    `{"type":"result","subtype":"error_max_turns","is_error":true,"num_turns":30}`,
+			wantKind: ErrorMaxTurns,
+			wantText: "max turns after 30 turns",
+		},
+		{
+			name:     "error during execution",
+			input:    `{"type":"result","subtype":"error_during_execution","is_error":true}`,
+			wantKind: ErrorExecution,
+			wantText: "execution error",
+		},
+		{
+			name:     "api error",
+			input:    `{"type":"result","subtype":"success","is_error":true,"result":"API Error: 529 overloaded"}`,
+			wantKind: ErrorAPI,
+			wantText: "API Error: 529 overloaded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseStreamJSON(strings.NewReader(tt.input), events.NewNullEmitter())
+
+			if tt.wantKind == "" {
+				if err != nil {
+					t.Fatalf("ParseStreamJSON() error = %v; want nil", err)
+				}
+				return
+			}
+
+			var resultErr *ResultError
+			if !errors.As(err, &resultErr) {
+				t.Fatalf("ParseStreamJSON() error = %v; want *ResultError", err)
+			}
+			if resultErr.Kind != tt.wantKind {
+				t.Errorf("Kind = %q; want %q", resultErr.Kind, tt.wantKind)
+			}
+			if !strings.Contains(err.Error(), tt.wantText) {
+				t.Errorf("Error() = %q; want to contain %q", err.Error(), tt.wantText)
+			}
+		})
+	}
+}
+
+func TestFailureReason(t *testing.T) {
+	wrapped := fmt.Errorf("round 2: %w", &ResultError{Kind: ErrorAPI})
+
+	if got := FailureReason(wrapped); got != string(ErrorAPI) {
+		t.Errorf("FailureReason(wrapped) = %q; want %q", got, ErrorAPI)
+	}
+	if got := FailureReason(errors.New("claude CLI failed: exit status 1")); got != "" {
+		t.Errorf("FailureReason(other) = %q; want empty", got)
+	}
+}
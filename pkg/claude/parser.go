@@ -10,13 +10,16 @@ import (
 	"github.com/LinHanLab/agent-exec/pkg/events"
 )
 
-// ParseStreamJSON parses streaming JSON output from claude CLI and returns the final result text
-func ParseStreamJSON(reader io.Reader, emitter events.Emitter) (string, error) {
+// ParseStreamJSON parses streaming JSON output from claude CLI and returns the final result.
+// A result message reporting a failure is returned as a *ResultError.
+func ParseStreamJSON(reader io.Reader, emitter events.Emitter) (Result, error) {
 	scanner := bufio.NewScanner(reader)
 	buf := make([]byte, 0, 1024*1024)
 	scanner.Buffer(buf, 10*1024*1024)
 
-	var resultText string
+	var result Result
+	var resultErr *ResultError
+	tools := newToolTracker()
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -26,49 +29,163 @@ func ParseStreamJSON(reader io.Reader, emitter events.Emitter) (string, error) {
 
 		var msg ClaudeMessage
 		if err := json.Unmarshal([]byte(line), &msg); err != nil {
-			return "", fmt.Errorf("failed to parse JSON output: %w", err)
+			return result, fmt.Errorf("failed to parse JSON output: %w", err)
 		}
 
 		switch msg.Type {
+		case "system":
+			if msg.Subtype == "init" {
+				emitter.Emit(events.EventClaudeSessionStarted, sessionStartedData(msg))
+			}
 		case "assistant":
 			for _, content := range msg.Message.Content {
 				switch content.Type {
 				case "text":
 					emitter.Emit(events.EventClaudeAssistantMessage, events.AssistantMessageData{
-						Text: content.Text,
+						Text:            content.Text,
+						ParentToolUseID: msg.ParentToolUseID,
 					})
+				case "thinking":
+					if content.Thinking != "" {
+						emitter.Emit(events.EventClaudeThinking, events.ThinkingData{
+							Text:            content.Thinking,
+							ParentToolUseID: msg.ParentToolUseID,
+						})
+					}
 				case "tool_use":
+					tools.start(content.ID, content.Name)
 					emitter.Emit(events.EventClaudeToolUse, events.ToolUseData{
-						Name:  content.Name,
-						Input: content.Input,
+						ID:              content.ID,
+						Name:            content.Name,
+						Input:           content.Input,
+						ParentToolUseID: msg.ParentToolUseID,
 					})
 				}
 			}
 		case "user":
 			for _, content := range msg.Message.Content {
-				if content.Type == "tool_result" && content.Content != nil {
-					resultStr := contentToString(content.Content)
-					if resultStr != "" {
-						emitter.Emit(events.EventClaudeToolResult, events.ToolResultData{
-							Content: resultStr,
-						})
-					}
+				if content.Type != "tool_result" {
+					continue
+				}
+				name, duration := tools.finish(content.ToolUseID, content.IsError)
+				resultStr := contentToString(content.Content)
+				if resultStr != "" || content.IsError {
+					emitter.Emit(events.EventClaudeToolResult, events.ToolResultData{
+						ToolUseID:       content.ToolUseID,
+						Name:            name,
+						Duration:        duration,
+						IsError:         content.IsError,
+						Content:         resultStr,
+						ParentToolUseID: msg.ParentToolUseID,
+					})
 				}
 			}
 		case "result":
 			if msg.Result != "" {
-				resultText = msg.Result
+				result.Text = msg.Result
 			}
+			result.CostUSD = msg.TotalCostUSD
+			result.NumTurns = msg.NumTurns
+			result.SessionID = msg.SessionID
+			resultErr = resultError(msg)
 			if msg.DurationMs > 0 {
-				duration := time.Duration(msg.DurationMs) * time.Millisecond
+				result.Duration = time.Duration(msg.DurationMs) * time.Millisecond
 				emitter.Emit(events.EventClaudeExecutionResult, events.ExecutionResultData{
-					Duration: duration,
+					Duration: result.Duration,
+					CostUSD:  msg.TotalCostUSD,
+					Tools:    tools.summary(),
+
+					InputTokens:  msg.Usage.InputTokens + msg.Usage.CacheCreationInputTokens + msg.Usage.CacheReadInputTokens,
+					OutputTokens: msg.Usage.OutputTokens,
 				})
 			}
 		}
 	}
 
-	return resultText, scanner.Err()
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+	if resultErr != nil {
+		return result, resultErr
+	}
+	return result, nil
+}
+
+// toolCall is a tool call waiting for its result
+type toolCall struct {
+	name    string
+	started time.Time
+}
+
+// toolTracker pairs tool results with their calls and sums up each tool's timings
+type toolTracker struct {
+	inFlight map[string]toolCall
+	timings  map[string]*events.ToolTiming
+	order    []string // Tool names in order of first use
+}
+
+func newToolTracker() *toolTracker {
+	return &toolTracker{
+		inFlight: make(map[string]toolCall),
+		timings:  make(map[string]*events.ToolTiming),
+	}
+}
+
+// start records a tool call
+func (t *toolTracker) start(id, name string) {
+	if id != "" {
+		t.inFlight[id] = toolCall{name: name, started: time.Now()}
+	}
+	if _, ok := t.timings[name]; !ok {
+		t.timings[name] = &events.ToolTiming{Name: name}
+		t.order = append(t.order, name)
+	}
+	t.timings[name].Calls++
+}
+
+// finish pairs a result with its call and returns the tool name and latency.
+// Results for unknown calls return an empty name.
+func (t *toolTracker) finish(id string, isError bool) (string, time.Duration) {
+	call, ok := t.inFlight[id]
+	if !ok {
+		return "", 0
+	}
+	delete(t.inFlight, id)
+
+	duration := time.Since(call.started)
+	timing := t.timings[call.name]
+	timing.Duration += duration
+	if isError {
+		timing.Errors++
+	}
+	return call.name, duration
+}
+
+// summary returns the per-tool timings in order of first use
+func (t *toolTracker) summary() []events.ToolTiming {
+	var summary []events.ToolTiming
+	for _, name := range t.order {
+		summary = append(summary, *t.timings[name])
+	}
+	return summary
+}
+
+// sessionStartedData converts a system/init message to event data
+func sessionStartedData(msg ClaudeMessage) events.SessionStartedData {
+	data := events.SessionStartedData{
+		SessionID:      msg.SessionID,
+		Model:          msg.Model,
+		Cwd:            msg.Cwd,
+		Tools:          msg.Tools,
+		PermissionMode: msg.PermissionMode,
+	}
+	for _, server := range msg.MCPServers {
+		data.MCPServers = append(data.MCPServers, events.MCPServerStatus{
+			Name:   server.Name,
+			Status: server.Status,
+		})
+	}
+	return data
 }
 
 // contentToString converts content (string or array) to string
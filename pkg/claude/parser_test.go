@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/LinHanLab/agent-exec/pkg/display"
 	"github.com/LinHanLab/agent-exec/pkg/events"
@@ -39,6 +40,54 @@ func TestParseStreamJSON(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name:           "system init message",
+			input:          `{"type":"system","subtype":"init","session_id":"abc-123","model":"claude-sonnet-4-5","cwd":"/work","tools":["Read","Edit","Bash"],"mcp_servers":[{"name":"github","status":"connected"}],"permissionMode":"default"}`,
+			expectedResult: "",
+			checkOutput: func(t *testing.T, output string) {
+				stripped := stripANSI(output)
+				for _, want := range []string{"🪪", "claude-sonnet-4-5", "abc-123", "Read, Edit, Bash", "github (connected)", "default"} {
+					if !strings.Contains(stripped, want) {
+						t.Errorf("Expected output to contain %q, got %q", want, stripped)
+					}
+				}
+			},
+			expectError: false,
+		},
+		{
+			name:           "other system messages are ignored",
+			input:          `{"type":"system","subtype":"compact_boundary"}`,
+			expectedResult: "",
+			checkOutput: func(t *testing.T, output string) {
+				if output != "" {
+					t.Errorf("Expected empty output, got %q", output)
+				}
+			},
+			expectError: false,
+		},
+		{
+			name:           "thinking block",
+			input:          `{"type":"assistant","message":{"content":[{"type":"thinking","thinking":"Consider the edge cases"}]}}`,
+			expectedResult: "",
+			checkOutput: func(t *testing.T, output string) {
+				stripped := stripANSI(output)
+				if !strings.Contains(stripped, "🧠") || !strings.Contains(stripped, "Consider the edge cases") {
+					t.Errorf("Expected thinking output, got %q", stripped)
+				}
+			},
+		},
+		{
+			name:           "subagent message is indented",
+			input:          `{"type":"assistant","parent_tool_use_id":"toolu_1","message":{"content":[{"type":"text","text":"From subagent"}]}}`,
+			expectedResult: "",
+			checkOutput: func(t *testing.T, output string) {
+				for _, line := range strings.Split(output, "\n") {
+					if strings.Contains(line, "From subagent") && !strings.HasPrefix(line, "        ") {
+						t.Errorf("Expected subagent text to be nested under its tool call, got %q", line)
+					}
+				}
+			},
+		},
 		{
 			name:           "assistant text message",
 			input:          `{"type":"assistant","message":{"content":[{"type":"text","text":"Hello world"}]}}`,
@@ -241,8 +290,8 @@ func TestParseStreamJSON(t *testing.T) {
 				t.Errorf("ParseStreamJSON() unexpected error: %v", err)
 			}
 
-			if result != tt.expectedResult {
-				t.Errorf("ParseStreamJSON() result = %q; want %q", result, tt.expectedResult)
+			if result.Text != tt.expectedResult {
+				t.Errorf("ParseStreamJSON() result = %q; want %q", result.Text, tt.expectedResult)
 			}
 
 			output := writer.String()
@@ -253,6 +302,110 @@ func TestParseStreamJSON(t *testing.T) {
 	}
 }
 
+func TestParseStreamJSON_ResultMetadata(t *testing.T) {
+	input := `{"type":"result","result":"done","duration_ms":2500,"total_cost_usd":0.125,"num_turns":4,"session_id":"abc-123"}`
+
+	result, err := ParseStreamJSON(strings.NewReader(input), events.NewNullEmitter())
+	if err != nil {
+		t.Fatalf("ParseStreamJSON() unexpected error: %v", err)
+	}
+
+	if result.Text != "done" {
+		t.Errorf("Text = %q; want %q", result.Text, "done")
+	}
+	if result.CostUSD != 0.125 {
+		t.Errorf("CostUSD = %v; want 0.125", result.CostUSD)
+	}
+	if result.NumTurns != 4 {
+		t.Errorf("NumTurns = %d; want 4", result.NumTurns)
+	}
+	if result.SessionID != "abc-123" {
+		t.Errorf("SessionID = %q; want %q", result.SessionID, "abc-123")
+	}
+	if result.Duration != 2500*time.Millisecond {
+		t.Errorf("Duration = %v; want 2.5s", result.Duration)
+	}
+}
+
+func TestParseStreamJSON_ResultUsage(t *testing.T) {
+	input := `{"type":"result","result":"done","duration_ms":1000,"usage":{"input_tokens":10,"cache_creation_input_tokens":200,"cache_read_input_tokens":3000,"output_tokens":45}}`
+
+	emitter := events.NewChannelEmitter(10)
+	if _, err := ParseStreamJSON(strings.NewReader(input), emitter); err != nil {
+		t.Fatalf("ParseStreamJSON() unexpected error: %v", err)
+	}
+	emitter.Close()
+
+	event := <-emitter.Subscribe()
+	data, ok := event.Data.(events.ExecutionResultData)
+	if !ok {
+		t.Fatalf("got %s event; want execution result", event.Type)
+	}
+	if data.InputTokens != 3210 || data.OutputTokens != 45 {
+		t.Errorf("tokens = %d in, %d out; want 3210 in, 45 out", data.InputTokens, data.OutputTokens)
+	}
+}
+
+func TestParseStreamJSON_ToolPairing(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_1","name":"Read","input":{}},{"type":"tool_use","id":"toolu_2","name":"Bash","input":{}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_2","content":"boom","is_error":true}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"file contents"}]}}`,
+		`{"type":"assistant","message":{"content":[{"type":"tool_use","id":"toolu_3","name":"Read","input":{}}]}}`,
+		`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_3","content":"more"}]}}`,
+		`{"type":"result","result":"done","duration_ms":1000}`,
+	}, "\n")
+
+	emitter := events.NewChannelEmitter(100)
+	if _, err := ParseStreamJSON(strings.NewReader(input), emitter); err != nil {
+		t.Fatalf("ParseStreamJSON() unexpected error: %v", err)
+	}
+	emitter.Close()
+
+	var results []events.ToolResultData
+	var summary []events.ToolTiming
+	for event := range emitter.Subscribe() {
+		switch data := event.Data.(type) {
+		case events.ToolResultData:
+			results = append(results, data)
+		case events.ExecutionResultData:
+			summary = data.Tools
+		}
+	}
+
+	wantResults := []struct {
+		id, name string
+		isError  bool
+	}{
+		{"toolu_2", "Bash", true},
+		{"toolu_1", "Read", false},
+		{"toolu_3", "Read", false},
+	}
+	if len(results) != len(wantResults) {
+		t.Fatalf("got %d tool results; want %d", len(results), len(wantResults))
+	}
+	for i, want := range wantResults {
+		got := results[i]
+		if got.ToolUseID != want.id || got.Name != want.name || got.IsError != want.isError {
+			t.Errorf("result %d = {%s %s %v}; want {%s %s %v}", i, got.ToolUseID, got.Name, got.IsError, want.id, want.name, want.isError)
+		}
+	}
+
+	wantSummary := []events.ToolTiming{
+		{Name: "Read", Calls: 2},
+		{Name: "Bash", Calls: 1, Errors: 1},
+	}
+	if len(summary) != len(wantSummary) {
+		t.Fatalf("got %d tool timings; want %d", len(summary), len(wantSummary))
+	}
+	for i, want := range wantSummary {
+		got := summary[i]
+		if got.Name != want.Name || got.Calls != want.Calls || got.Errors != want.Errors {
+			t.Errorf("timing %d = %+v; want %+v", i, got, want)
+		}
+	}
+}
+
 // stripANSI removes ANSI color codes from a string
 func stripANSI(s string) string {
 	result := ""
@@ -2,6 +2,7 @@ package claude
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -9,10 +10,19 @@ import (
 	"github.com/LinHanLab/agent-exec/pkg/events"
 )
 
+// BinaryEnv names the environment variable that overrides the claude executable
+const BinaryEnv = "AGENT_EXEC_CLAUDE"
+
 // PromptOptions holds optional configuration for running prompts
 type PromptOptions struct {
 	SystemPrompt       string // Replace entire system prompt (empty = use defaults)
 	AppendSystemPrompt string // Append to default system prompt (empty = use defaults)
+	Dir                string // Working directory for the claude process (empty = current directory)
+	Resume             string // Session ID to continue (empty = start a new session)
+
+	Cassette *Cassette // Record invocations to, or replay them from, a cassette (nil = run the CLI)
+	Budget   *Budget   // Spending limit shared with other invocations (nil = unlimited)
+	Control  *Control  // Pauses or aborts the run before each invocation (nil = never)
 }
 
 // BuildClaudeArgs constructs the claude CLI arguments based on options
@@ -25,15 +35,22 @@ func (opts *PromptOptions) BuildClaudeArgs(prompt string) []string {
 	if opts.AppendSystemPrompt != "" {
 		args = append(args, "--append-system-prompt", opts.AppendSystemPrompt)
 	}
+	if opts.Resume != "" {
+		args = append(args, "--resume", opts.Resume)
+	}
 
 	return args
 }
 
-// getCwdInfo retrieves current working directory and file list with error handling
-func getCwdInfo(emitter events.Emitter) (cwd, fileList string, err error) {
-	cwd, err = os.Getwd()
-	if err != nil {
-		return "", "", fmt.Errorf("failed to get cwd: %w", err)
+// getCwdInfo retrieves the working directory claude runs in (dir, or the current
+// directory when empty) and its file list with error handling
+func getCwdInfo(dir string, emitter events.Emitter) (cwd, fileList string, err error) {
+	cwd = dir
+	if cwd == "" {
+		cwd, err = os.Getwd()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get cwd: %w", err)
+		}
 	}
 
 	files, err := os.ReadDir(cwd)
@@ -50,15 +67,43 @@ func getCwdInfo(emitter events.Emitter) (cwd, fileList string, err error) {
 	return
 }
 
-// RunPrompt executes a single prompt with claude CLI and returns the final result text
-func RunPrompt(prompt string, opts *PromptOptions, emitter events.Emitter) (string, error) {
+// binaryPath returns the claude executable to run, honoring BinaryEnv
+func binaryPath() string {
+	if path := os.Getenv(BinaryEnv); path != "" {
+		return path
+	}
+	return "claude"
+}
+
+// RunPrompt executes a single prompt with claude CLI and returns the final result.
+// It returns an error wrapping ErrBudgetExhausted instead of running once
+// opts.Budget is used up. While opts.Control is paused it waits before
+// running, and it returns ErrAborted once the control is aborted.
+func RunPrompt(prompt string, opts *PromptOptions, emitter events.Emitter) (Result, error) {
 	if err := ValidatePrompt(prompt); err != nil {
-		return "", err
+		return Result{}, err
+	}
+
+	if opts == nil {
+		opts = &PromptOptions{}
 	}
 
-	cwd, fileList, err := getCwdInfo(emitter)
+	if err := opts.Control.wait(); err != nil {
+		return Result{}, err
+	}
+	if err := opts.Budget.check(); err != nil {
+		return Result{}, err
+	}
+	result, err := runPrompt(prompt, opts, emitter)
+	opts.Budget.add(result.CostUSD)
+	return result, err
+}
+
+// runPrompt runs the CLI, or replays a recording, for RunPrompt
+func runPrompt(prompt string, opts *PromptOptions, emitter events.Emitter) (Result, error) {
+	cwd, fileList, err := getCwdInfo(opts.Dir, emitter)
 	if err != nil {
-		return "", err
+		return Result{}, err
 	}
 
 	emitter.Emit(events.EventRunPromptStarted, events.RunPromptStartedData{
@@ -68,30 +113,59 @@ func RunPrompt(prompt string, opts *PromptOptions, emitter events.Emitter) (stri
 		FileList: fileList,
 	})
 
-	if opts == nil {
-		opts = &PromptOptions{}
+	if opts.Cassette != nil && opts.Cassette.Mode == CassetteReplay {
+		return opts.Cassette.replay(prompt, emitter)
 	}
+
 	args := opts.BuildClaudeArgs(prompt)
-	cmd := exec.Command("claude", args...)
+	cmd := exec.Command(binaryPath(), args...)
+	cmd.Dir = opts.Dir
 	cmd.Stderr = os.Stderr
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return "", fmt.Errorf("failed to create stdout pipe: %w", err)
+		return Result{}, fmt.Errorf("failed to create stdout pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("failed to start claude CLI: %w", err)
+		return Result{}, fmt.Errorf("failed to start claude CLI: %w", err)
 	}
 
-	result, parseErr := ParseStreamJSON(stdout, emitter)
+	// Record only once the CLI runs, so a failed start leaves no empty recording behind
+	var rec *recording
+	var output io.Reader = stdout
+	if opts.Cassette != nil && opts.Cassette.Mode == CassetteRecord {
+		rec, err = opts.Cassette.startRecording(prompt, args, cwd)
+		if err != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return Result{}, err
+		}
+		output = rec.teeStream(stdout)
+	}
+
+	result, parseErr := ParseStreamJSON(output, emitter)
 	if parseErr != nil {
-		_ = cmd.Wait()
-		return "", parseErr
+		// Keep the rest of the stream so the recording reproduces the failure
+		if rec != nil {
+			_, _ = io.Copy(io.Discard, output)
+		}
+		waitErr := cmd.Wait()
+		if rec != nil {
+			_ = rec.finish(exitCode(waitErr))
+		}
+		// The partial result keeps the session ID and cost of a failed run
+		return result, parseErr
 	}
 
-	if err := cmd.Wait(); err != nil {
-		return "", fmt.Errorf("claude CLI failed: %w", err)
+	waitErr := cmd.Wait()
+	if rec != nil {
+		if err := rec.finish(exitCode(waitErr)); err != nil {
+			return Result{}, err
+		}
+	}
+	if waitErr != nil {
+		return Result{}, fmt.Errorf("claude CLI failed: %w", waitErr)
 	}
 
 	return result, nil
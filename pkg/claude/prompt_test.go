@@ -1,10 +1,20 @@
 package claude
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude/claudetest"
+	"github.com/LinHanLab/agent-exec/pkg/events"
 )
 
+func TestMain(m *testing.M) {
+	claudetest.Main()
+	os.Exit(m.Run())
+}
+
 func TestPromptOptions_BuildClaudeArgs(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -43,6 +53,14 @@ func TestPromptOptions_BuildClaudeArgs(t *testing.T) {
 			prompt: "test prompt",
 			want:   []string{"--verbose", "--output-format", "stream-json", "-p", "test prompt", "--system-prompt", "You are a helpful assistant", "--append-system-prompt", "Focus on security"},
 		},
+		{
+			name: "with resume",
+			opts: &PromptOptions{
+				Resume: "abc-123",
+			},
+			prompt: "test prompt",
+			want:   []string{"--verbose", "--output-format", "stream-json", "-p", "test prompt", "--resume", "abc-123"},
+		},
 		{
 			name: "empty strings are ignored",
 			opts: &PromptOptions{
@@ -63,3 +81,44 @@ func TestPromptOptions_BuildClaudeArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestRunPrompt_FakeClaude(t *testing.T) {
+	fake := claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{
+		{Match: "fail", ExitCode: 1},
+		{Files: map[string]string{"hello.txt": "hello\n"}, Text: "wrote hello.txt", CostUSD: 0.25},
+	}})
+	dir := t.TempDir()
+
+	emitter := events.NewChannelEmitter(100)
+	result, err := RunPrompt("write hello", &PromptOptions{Dir: dir}, emitter)
+	emitter.Close()
+	if err != nil {
+		t.Fatalf("RunPrompt failed: %v", err)
+	}
+
+	if result.Text != "wrote hello.txt" || result.CostUSD != 0.25 {
+		t.Errorf("got result %+v; want text and cost from the script", result)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "hello.txt")); err != nil || string(content) != "hello\n" {
+		t.Errorf("got hello.txt %q (err %v); want written in the prompt's directory", content, err)
+	}
+
+	var toolUses int
+	for event := range emitter.Subscribe() {
+		if event.Type == events.EventClaudeToolUse {
+			toolUses++
+		}
+	}
+	if toolUses != 1 {
+		t.Errorf("got %d tool use events; want 1", toolUses)
+	}
+
+	if _, err := RunPrompt("please fail", &PromptOptions{Dir: dir}, events.NewNullEmitter()); err == nil {
+		t.Error("RunPrompt succeeded; want error for non-zero exit")
+	}
+
+	calls := fake.Calls(t)
+	if len(calls) != 2 || calls[0].Prompt != "write hello" || calls[0].Dir != dir {
+		t.Errorf("got calls %+v; want two calls starting with \"write hello\" in %s", calls, dir)
+	}
+}
@@ -1,11 +1,52 @@
 package claude
 
+import "time"
+
+// Result holds the outcome of a single claude CLI invocation
+type Result struct {
+	Text      string        // Final result text
+	CostUSD   float64       // Total cost reported by the CLI
+	Duration  time.Duration // Execution duration reported by the CLI
+	NumTurns  int           // Number of agent turns
+	SessionID string        // Session identifier
+}
+
 // ClaudeMessage represents the main JSON structure from claude CLI
 type ClaudeMessage struct {
-	Type       string        `json:"type"`
-	Message    MessageDetail `json:"message,omitempty"`
-	Result     string        `json:"result,omitempty"`
-	DurationMs int           `json:"duration_ms,omitempty"`
+	Type         string        `json:"type"`
+	Subtype      string        `json:"subtype,omitempty"`
+	Message      MessageDetail `json:"message,omitempty"`
+	Result       string        `json:"result,omitempty"`
+	IsError      bool          `json:"is_error,omitempty"`
+	DurationMs   int           `json:"duration_ms,omitempty"`
+	TotalCostUSD float64       `json:"total_cost_usd,omitempty"`
+	NumTurns     int           `json:"num_turns,omitempty"`
+	SessionID    string        `json:"session_id,omitempty"`
+	Usage        Usage         `json:"usage,omitempty"`
+
+	// ParentToolUseID is set on messages from a subagent started by a Task tool call
+	ParentToolUseID string `json:"parent_tool_use_id,omitempty"`
+
+	// Fields of the system/init message
+	Model          string      `json:"model,omitempty"`
+	Cwd            string      `json:"cwd,omitempty"`
+	Tools          []string    `json:"tools,omitempty"`
+	MCPServers     []MCPServer `json:"mcp_servers,omitempty"`
+	PermissionMode string      `json:"permissionMode,omitempty"`
+}
+
+// Usage holds the token counts of the result message
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// MCPServer is an MCP server's connection status from the system/init message
+type MCPServer struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
 }
 
 // MessageDetail contains the message content
@@ -15,9 +56,13 @@ type MessageDetail struct {
 
 // ContentItem represents individual content parts
 type ContentItem struct {
-	Type    string                 `json:"type"`
-	Text    string                 `json:"text,omitempty"`
-	Name    string                 `json:"name,omitempty"`
-	Input   map[string]interface{} `json:"input,omitempty"`
-	Content interface{}            `json:"content,omitempty"` // can be string or array
+	Type      string                 `json:"type"`
+	Text      string                 `json:"text,omitempty"`
+	Thinking  string                 `json:"thinking,omitempty"`
+	ID        string                 `json:"id,omitempty"` // tool_use only
+	Name      string                 `json:"name,omitempty"`
+	Input     map[string]interface{} `json:"input,omitempty"`
+	ToolUseID string                 `json:"tool_use_id,omitempty"` // tool_result only
+	IsError   bool                   `json:"is_error,omitempty"`    // tool_result only
+	Content   interface{}            `json:"content,omitempty"`     // can be string or array
 }
@@ -0,0 +1,271 @@
+package clean
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+// Options controls which candidate branches are removed
+type Options struct {
+	DryRun      bool          // Only report what would be deleted
+	OlderThan   time.Duration // Only remove candidates created at least this long ago (0 = any age)
+	KeepWinners bool          // Keep the final winner of each run
+}
+
+// Candidate is a branch created by agent-exec, identified through its lineage note
+type Candidate struct {
+	Branch       string
+	Commit       string
+	Record       git.LineageRecord
+	Worktree     string // Worktree under .agent-exec/worktrees holding the branch, if any
+	CheckedOutIn string // Working tree agent-exec did not create that has the branch checked out
+}
+
+// Leftover is the worktree and branch an isolated run left behind, found
+// through the run record agent-exec wrote when it created the worktree: an
+// isolated loop run, or an isolated evolve run killed before it could clean up
+type Leftover struct {
+	RunID        string
+	Branch       string // Branch created with the worktree, if it still exists
+	Worktree     string // The run's worktree under .agent-exec/worktrees, if it still exists
+	CheckedOutIn string // Another working tree that has the branch checked out
+}
+
+// notedBranches maps each branch whose tip commit carries a lineage note naming it to that note
+func notedBranches(branches map[string]string, records []git.LineageRecord) map[string]git.LineageRecord {
+	byCommit := make(map[string]git.LineageRecord, len(records))
+	for _, record := range records {
+		byCommit[record.Commit] = record
+	}
+
+	noted := make(map[string]git.LineageRecord)
+	for branch, commit := range branches {
+		if record, ok := byCommit[commit]; ok && record.Branch == branch {
+			noted[branch] = record
+		}
+	}
+	return noted
+}
+
+// FindCandidates matches local branches against lineage records and applies the options.
+// A branch is only considered when its tip commit carries a lineage note naming that branch.
+func FindCandidates(branches map[string]string, worktrees []git.Worktree, records []git.LineageRecord, opts Options, now time.Time) []Candidate {
+	var candidates []Candidate
+	for branch, record := range notedBranches(branches, records) {
+		commit := branches[branch]
+		if opts.KeepWinners && record.Final {
+			continue
+		}
+		if opts.OlderThan > 0 && now.Sub(record.CreatedAt) < opts.OlderThan {
+			continue
+		}
+
+		candidate := Candidate{Branch: branch, Commit: commit, Record: record}
+		for _, wt := range worktrees {
+			if wt.Branch != branch {
+				continue
+			}
+			// Only worktrees agent-exec created are removed; others may hold the user's work
+			if !wt.Main && git.IsRunWorktree(wt.Path) {
+				candidate.Worktree = wt.Path
+			} else {
+				candidate.CheckedOutIn = wt.Path
+			}
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Record.CreatedAt.Before(candidates[j].Record.CreatedAt)
+	})
+
+	return candidates
+}
+
+// FindLeftovers matches the recorded runs against the worktrees and branches
+// that still exist and applies the options. A run's branch holds its result,
+// so KeepWinners keeps it. A branch carrying a lineage note is a candidate and
+// left to FindCandidates; runs with nothing left are skipped.
+func FindLeftovers(branches map[string]string, worktrees []git.Worktree, records []git.LineageRecord, runs []git.RunRecord, opts Options, now time.Time) []Leftover {
+	noted := notedBranches(branches, records)
+
+	var leftovers []Leftover
+	for _, run := range runs {
+		if opts.OlderThan > 0 && now.Sub(run.CreatedAt) < opts.OlderThan {
+			continue
+		}
+
+		leftover := Leftover{RunID: run.RunID}
+		if _, exists := branches[run.Branch]; exists && run.Branch != "" {
+			if _, isCandidate := noted[run.Branch]; !isCandidate {
+				leftover.Branch = run.Branch
+			}
+		}
+		for _, wt := range worktrees {
+			switch {
+			case !wt.Main && filepath.Clean(wt.Path) == filepath.Clean(run.Worktree):
+				leftover.Worktree = wt.Path
+			case leftover.Branch != "" && wt.Branch == leftover.Branch:
+				leftover.CheckedOutIn = wt.Path
+			}
+		}
+
+		if leftover.Branch == "" && leftover.Worktree == "" {
+			continue
+		}
+		if opts.KeepWinners && leftover.Branch != "" {
+			continue
+		}
+		leftovers = append(leftovers, leftover)
+	}
+
+	sort.Slice(leftovers, func(i, j int) bool {
+		return leftovers[i].RunID < leftovers[j].RunID
+	})
+
+	return leftovers
+}
+
+// Run finds leftover candidate branches, run branches and worktrees and removes them
+func Run(client git.Repo, opts Options, w io.Writer) error {
+	branches, err := client.ListBranches()
+	if err != nil {
+		return err
+	}
+	worktrees, err := client.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	records, err := client.ReadLineage()
+	if err != nil {
+		return err
+	}
+	runs, err := client.ReadRuns()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	candidates := FindCandidates(branches, worktrees, records, opts, now)
+	leftovers := FindLeftovers(branches, worktrees, records, runs, opts, now)
+	if len(candidates) == 0 && len(leftovers) == 0 {
+		_, err := fmt.Fprintln(w, "Nothing to clean.")
+		return err
+	}
+
+	if len(candidates) > 0 {
+		if err := removeCandidates(client, candidates, opts, w); err != nil {
+			return err
+		}
+	}
+	if len(leftovers) > 0 {
+		return removeLeftovers(client, leftovers, opts, w)
+	}
+	return nil
+}
+
+// removeCandidates deletes candidate branches and the worktrees holding them
+func removeCandidates(client git.Repo, candidates []Candidate, opts Options, w io.Writer) error {
+	removed := 0
+	for _, candidate := range candidates {
+		desc := describe(candidate)
+
+		if candidate.CheckedOutIn != "" {
+			fmt.Fprintf(w, "⏭️  Skipping %s: checked out in %s\n", desc, candidate.CheckedOutIn)
+			continue
+		}
+
+		if opts.DryRun {
+			if candidate.Worktree != "" {
+				fmt.Fprintf(w, "Would remove worktree %s\n", candidate.Worktree)
+			}
+			fmt.Fprintf(w, "Would delete %s\n", desc)
+			removed++
+			continue
+		}
+
+		if candidate.Worktree != "" {
+			if err := client.RemoveWorktree(candidate.Worktree); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "🗑️  Removed worktree %s\n", candidate.Worktree)
+		}
+		if err := client.DeleteBranch(candidate.Branch); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "🗑️  Deleted %s\n", desc)
+		removed++
+	}
+
+	verb := "Deleted"
+	if opts.DryRun {
+		verb = "Would delete"
+	}
+	_, err := fmt.Fprintf(w, "%s %d of %d candidate branches.\n", verb, removed, len(candidates))
+	return err
+}
+
+// removeLeftovers removes leftover run worktrees, deletes run branches and
+// forgets the runs
+func removeLeftovers(client git.Repo, leftovers []Leftover, opts Options, w io.Writer) error {
+	removed := 0
+	for _, leftover := range leftovers {
+		if leftover.CheckedOutIn != "" {
+			fmt.Fprintf(w, "⏭️  Skipping run branch %s: checked out in %s\n", leftover.Branch, leftover.CheckedOutIn)
+			continue
+		}
+
+		if opts.DryRun {
+			if leftover.Worktree != "" {
+				fmt.Fprintf(w, "Would remove worktree %s (run %s)\n", leftover.Worktree, leftover.RunID)
+			}
+			if leftover.Branch != "" {
+				fmt.Fprintf(w, "Would delete run branch %s\n", leftover.Branch)
+			}
+			removed++
+			continue
+		}
+
+		if leftover.Worktree != "" {
+			if err := client.RemoveWorktree(leftover.Worktree); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "🗑️  Removed worktree %s (run %s)\n", leftover.Worktree, leftover.RunID)
+		}
+		if leftover.Branch != "" {
+			if err := client.DeleteBranch(leftover.Branch); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "🗑️  Deleted run branch %s\n", leftover.Branch)
+		}
+		if err := client.DeleteRun(leftover.RunID); err != nil {
+			return err
+		}
+		removed++
+	}
+
+	verb := "Removed"
+	if opts.DryRun {
+		verb = "Would remove"
+	}
+	_, err := fmt.Fprintf(w, "%s %d of %d leftover runs.\n", verb, removed, len(leftovers))
+	return err
+}
+
+// describe summarizes a candidate for output
+func describe(c Candidate) string {
+	round := "initial"
+	if c.Record.Round > 0 {
+		round = fmt.Sprintf("round %d", c.Record.Round)
+	}
+	desc := fmt.Sprintf("%s (run %s, %s, %s)", c.Branch, c.Record.RunID, round, c.Record.Verdict)
+	if c.Record.Final {
+		desc += " [winner]"
+	}
+	return desc
+}
@@ -0,0 +1,240 @@
+package clean
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+func TestFindCandidates(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	records := []git.LineageRecord{
+		{RunID: "run-1", Branch: "impl-old", Commit: "c1", Verdict: git.VerdictLost, CreatedAt: now.Add(-72 * time.Hour)},
+		{RunID: "run-1", Branch: "impl-win", Commit: "c2", Verdict: git.VerdictWon, Final: true, CreatedAt: now.Add(-71 * time.Hour)},
+		{RunID: "run-2", Branch: "impl-new", Commit: "c3", Verdict: git.VerdictPending, CreatedAt: now.Add(-time.Hour)},
+		{RunID: "run-2", Branch: "impl-wt", Commit: "c4", Verdict: git.VerdictPending, CreatedAt: now.Add(-30 * time.Minute)},
+		{RunID: "run-2", Branch: "impl-moved", Commit: "c5", Verdict: git.VerdictLost, CreatedAt: now.Add(-time.Hour)},
+	}
+
+	branches := map[string]string{
+		"main":       "c0",
+		"impl-old":   "c1",
+		"impl-win":   "c2",
+		"impl-new":   "c3",
+		"impl-wt":    "c4",
+		"impl-moved": "c9", // Branch moved on after the note was written
+		"impl-human": "c8", // Looks like a candidate but has no lineage note
+	}
+
+	worktrees := []git.Worktree{
+		{Path: "/repo", Branch: "impl-new", Main: true},
+		{Path: "/repo/.agent-exec/worktrees/run-2", Branch: "impl-wt"},
+		{Path: "/home/user/review", Branch: "impl-old"}, // Created by the user
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "all recognized candidates",
+			opts: Options{},
+			want: []string{"impl-old", "impl-win", "impl-new", "impl-wt"},
+		},
+		{
+			name: "keep winners",
+			opts: Options{KeepWinners: true},
+			want: []string{"impl-old", "impl-new", "impl-wt"},
+		},
+		{
+			name: "older than a day",
+			opts: Options{OlderThan: 24 * time.Hour},
+			want: []string{"impl-old", "impl-win"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindCandidates(branches, worktrees, records, tt.opts, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindCandidates() returned %d candidates; want %d (%v)", len(got), len(tt.want), got)
+			}
+			for i, branch := range tt.want {
+				if got[i].Branch != branch {
+					t.Errorf("candidate %d = %s; want %s", i, got[i].Branch, branch)
+				}
+			}
+		})
+	}
+
+	got := FindCandidates(branches, worktrees, records, Options{}, now)
+	for _, c := range got {
+		switch c.Branch {
+		case "impl-new":
+			if c.CheckedOutIn != "/repo" || c.Worktree != "" {
+				t.Errorf("got impl-new checked out in %q; want the main working tree /repo", c.CheckedOutIn)
+			}
+		case "impl-old":
+			if c.CheckedOutIn != "/home/user/review" || c.Worktree != "" {
+				t.Errorf("got impl-old checked out in %q with worktree %q; want the user's worktree left alone", c.CheckedOutIn, c.Worktree)
+			}
+		case "impl-wt":
+			if c.Worktree != "/repo/.agent-exec/worktrees/run-2" {
+				t.Errorf("Expected impl-wt worktree path, got %q", c.Worktree)
+			}
+		}
+	}
+}
+
+func TestFindLeftovers(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	records := []git.LineageRecord{
+		{RunID: "run-a", Branch: "agent-exec/run-a", Commit: "c1", Verdict: git.VerdictWon, Final: true},
+	}
+
+	runs := []git.RunRecord{
+		{RunID: "run-a", Worktree: "/repo/.agent-exec/worktrees/run-a", CreatedAt: now.Add(-72 * time.Hour)},                             // Evolve pr-branch finish, worktree gone
+		{RunID: "run-b", Branch: "agent-exec/run-b", Worktree: "/repo/.agent-exec/worktrees/run-b", CreatedAt: now.Add(-48 * time.Hour)}, // Isolated loop run
+		{RunID: "run-c", Worktree: "/repo/.agent-exec/worktrees/run-c", CreatedAt: now.Add(-30 * time.Hour)},                             // Killed isolated evolve run
+		{RunID: "run-d", Branch: "agent-exec/run-d", Worktree: "/repo/.agent-exec/worktrees/run-d", CreatedAt: now.Add(-time.Hour)},      // Worktree removed, branch checked out by the user
+		{RunID: "run-e", Branch: "agent-exec/run-e", Worktree: "/repo/.agent-exec/worktrees/run-e", CreatedAt: now.Add(-96 * time.Hour)}, // Nothing left
+	}
+
+	branches := map[string]string{
+		"main":                  "c0",
+		"agent-exec/run-a":      "c1",
+		"agent-exec/run-b":      "c2",
+		"agent-exec/run-d":      "c3",
+		"agent-exec/my-feature": "c5", // Created by the user, never recorded
+	}
+
+	worktrees := []git.Worktree{
+		{Path: "/repo", Branch: "main", Main: true},
+		{Path: "/repo/.agent-exec/worktrees/run-b", Branch: "agent-exec/run-b"},
+		{Path: "/repo/.agent-exec/worktrees/run-c"},
+		{Path: "/home/user/review", Branch: "agent-exec/run-d"},
+		{Path: "/home/user/feature", Branch: "agent-exec/my-feature"},
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "all leftovers",
+			opts: Options{},
+			want: []string{
+				"run-b agent-exec/run-b /repo/.agent-exec/worktrees/run-b",
+				"run-c  /repo/.agent-exec/worktrees/run-c",
+				"run-d agent-exec/run-d ",
+			},
+		},
+		{
+			name: "keep winners",
+			opts: Options{KeepWinners: true},
+			want: []string{"run-c  /repo/.agent-exec/worktrees/run-c"},
+		},
+		{
+			name: "older than a day",
+			opts: Options{OlderThan: 24 * time.Hour},
+			want: []string{
+				"run-b agent-exec/run-b /repo/.agent-exec/worktrees/run-b",
+				"run-c  /repo/.agent-exec/worktrees/run-c",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindLeftovers(branches, worktrees, records, runs, tt.opts, now)
+			var gotKeys []string
+			for _, l := range got {
+				gotKeys = append(gotKeys, l.RunID+" "+l.Branch+" "+l.Worktree)
+			}
+			if strings.Join(gotKeys, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("FindLeftovers() = %q; want %q", gotKeys, tt.want)
+			}
+		})
+	}
+
+	for _, l := range FindLeftovers(branches, worktrees, records, runs, Options{}, now) {
+		if l.Branch == "agent-exec/run-d" && l.CheckedOutIn != "/home/user/review" {
+			t.Errorf("got checked out in %q; want /home/user/review", l.CheckedOutIn)
+		}
+	}
+}
+
+func TestRun_RemovesLeftovers(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	if _, err := repo.AddWorktree("run-b", "main", "agent-exec/run-b"); err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if _, err := repo.AddWorktree("run-c", "main", ""); err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	if err := repo.Checkout("main"); err != nil {
+		t.Fatalf("Checkout failed: %v", err)
+	}
+	// A branch with the run prefix that agent-exec never recorded
+	if err := repo.CreateBranch("agent-exec/my-feature"); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+
+	var out strings.Builder
+	if err := Run(repo, Options{}, &out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	branches, _ := repo.ListBranches()
+	if _, ok := branches["agent-exec/run-b"]; ok {
+		t.Error("run branch was not deleted")
+	}
+	if _, ok := branches["agent-exec/my-feature"]; !ok {
+		t.Error("unrecorded agent-exec/my-feature branch was deleted")
+	}
+	worktrees, _ := repo.ListWorktrees()
+	if len(worktrees) != 1 || !worktrees[0].Main {
+		t.Errorf("got worktrees %+v; want only the main working tree", worktrees)
+	}
+	if runs, _ := repo.ReadRuns(); len(runs) != 0 {
+		t.Errorf("got run records %+v; want them deleted with the leftovers", runs)
+	}
+	if !strings.Contains(out.String(), "Removed 2 of 2 leftover runs.") {
+		t.Errorf("got output %q; want both leftover runs removed", out.String())
+	}
+}
+
+func TestRun_KeepsUserWorktree(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	if err := repo.CreateBranch("impl-aaaaaa"); err != nil {
+		t.Fatalf("CreateBranch failed: %v", err)
+	}
+	commit, _ := repo.ResolveCommit("impl-aaaaaa")
+	if err := repo.WriteLineage(&git.LineageRecord{RunID: "run-1", Branch: "impl-aaaaaa", Commit: commit, Verdict: git.VerdictLost}); err != nil {
+		t.Fatalf("WriteLineage failed: %v", err)
+	}
+	repo.AddFakeWorktree("/home/user/review", "impl-aaaaaa")
+
+	var out strings.Builder
+	if err := Run(repo, Options{}, &out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	branches, _ := repo.ListBranches()
+	if _, ok := branches["impl-aaaaaa"]; !ok {
+		t.Error("candidate checked out in the user's worktree was deleted")
+	}
+	worktrees, _ := repo.ListWorktrees()
+	if len(worktrees) != 2 {
+		t.Errorf("got worktrees %+v; want the user's worktree kept", worktrees)
+	}
+	if !strings.Contains(out.String(), "checked out in /home/user/review") {
+		t.Errorf("got output %q; want the skipped worktree reported", out.String())
+	}
+}
@@ -0,0 +1,201 @@
+package evolve
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude"
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+// Branches that can be checked out when evolve exits
+const (
+	OnExitWinner   = "winner"
+	OnExitOriginal = "original"
+)
+
+// incompleteSuffix is appended to a half-built challenger kept with KeepIncomplete
+const incompleteSuffix = "-incomplete"
+
+// validateOnExit checks the configured exit branch
+func validateOnExit(onExit string) error {
+	switch onExit {
+	case "", OnExitWinner, OnExitOriginal:
+		return nil
+	default:
+		return fmt.Errorf("unknown --on-exit value: %s (expected %s or %s)", onExit, OnExitWinner, OnExitOriginal)
+	}
+}
+
+// validateStash checks that stashed changes can be restored where the run
+// ends: they were taken from the original branch and only belong there
+func validateStash(cfg EvolveConfig) error {
+	if cfg.Stash && cfg.OnExit != OnExitOriginal {
+		return errors.New("--stash restores your changes on the original branch and requires --on-exit original")
+	}
+	return nil
+}
+
+// cleanup runs on every exit path. It deletes or keeps the challenger that was
+// still being built, checks out the winner or the original branch, restores
+// stashed changes and reports the final state. It returns the (possibly
+// reclassified) run error.
+func (r *EvolutionRunner) cleanup(runErr error) error {
+	outcome := "completed"
+	if runErr != nil {
+		outcome = "failed"
+		// A signal that arrived while Claude was running surfaces as a CLI failure
+		if errors.Is(runErr, claude.ErrInterrupted) || r.checkInterrupted() != nil {
+			outcome = "interrupted"
+			runErr = claude.ErrInterrupted
+		}
+	}
+
+	data := events.EvolveCleanupData{
+		Outcome: outcome,
+		Winner:  r.currentWinner,
+	}
+
+	if r.pendingBranch != "" {
+		data.IncompleteBranch = r.pendingBranch
+		action, err := r.resolveIncomplete(r.pendingBranch)
+		data.IncompleteAction = action
+		if err != nil {
+			data.Error = err.Error()
+		}
+		r.pendingBranch = ""
+	}
+
+	data.CheckedOut = r.checkoutOnExit(&data)
+
+	if err := r.restoreStash(data.CheckedOut); err != nil && data.Error == "" {
+		data.Error = err.Error()
+	}
+
+	r.emitter.Emit(events.EventEvolveCleanup, data)
+
+	if runErr == nil && data.Error != "" {
+		return fmt.Errorf("cleanup failed: %s", data.Error)
+	}
+	return runErr
+}
+
+// checkoutOnExit checks out the configured exit branch and returns what is
+// checked out in the user's working tree afterwards
+func (r *EvolutionRunner) checkoutOnExit(data *events.EvolveCleanupData) string {
+	// An isolated run's worktree is removed afterwards; the user's checkout never moved
+	if r.config.Isolate {
+		return r.originalBranch
+	}
+
+	// Once the winner is integrated into the original branch, stay there
+	target := r.originalBranch
+	integrated := r.finished && integratesIntoOriginal(r.config.Finish)
+	if r.config.OnExit != OnExitOriginal && r.currentWinner != "" && !integrated {
+		target = r.currentWinner
+	}
+
+	current, err := r.gitClient.GetCurrentBranch()
+	if err == nil && current != target {
+		err = r.gitClient.Checkout(target)
+	}
+	if err != nil {
+		if data.Error == "" {
+			data.Error = err.Error()
+		}
+		return current
+	}
+	return target
+}
+
+// resolveIncomplete deletes a half-built challenger or, with KeepIncomplete,
+// commits its work and renames it with the incomplete suffix. It returns
+// "deleted" or the branch's new name.
+func (r *EvolutionRunner) resolveIncomplete(branch string) (string, error) {
+	current, err := r.gitClient.GetCurrentBranch()
+	if err != nil {
+		return "", err
+	}
+
+	if r.config.KeepIncomplete || r.config.DebugKeepBranches {
+		if current == branch {
+			if err := r.gitClient.CommitAll("incomplete: the candidate was not finished"); err != nil {
+				return "", err
+			}
+		}
+		kept := branch + incompleteSuffix
+		if err := r.gitClient.RenameBranch(branch, kept); err != nil {
+			return "", err
+		}
+		return kept, r.recordIncomplete(kept)
+	}
+
+	if current == branch {
+		if err := r.gitClient.DiscardChanges(); err != nil {
+			return "", err
+		}
+		if err := r.gitClient.Checkout(r.baseRef); err != nil {
+			return "", err
+		}
+	}
+	if err := r.gitClient.DeleteBranch(branch); err != nil {
+		return "", err
+	}
+	return "deleted", nil
+}
+
+// failChallenger discards a challenger whose Claude run ended in an error
+// result (max turns, execution or API error) so the round continues with the
+// current winner. Other errors are returned unchanged and end the run.
+func (r *EvolutionRunner) failChallenger(branch string, roundNum int, runErr error) error {
+	reason := claude.FailureReason(runErr)
+	if reason == "" {
+		return runErr
+	}
+
+	action, err := r.resolveIncomplete(branch)
+	if err != nil {
+		return err
+	}
+	r.pendingBranch = ""
+	r.failed++
+
+	if err := r.gitClient.Checkout(r.currentWinner); err != nil {
+		return err
+	}
+
+	r.emitter.Emit(events.EventChallengerFailed, events.ChallengerFailedData{
+		Round:      roundNum,
+		BranchName: branch,
+		Reason:     reason,
+		Error:      runErr.Error(),
+		Action:     action,
+	})
+	return nil
+}
+
+// recordIncomplete writes a lineage note for a kept incomplete challenger so
+// that "agent-exec clean" can recognize it later
+func (r *EvolutionRunner) recordIncomplete(branch string) error {
+	commit, err := r.gitClient.ResolveCommit(branch)
+	if err != nil {
+		return err
+	}
+
+	parent := r.currentWinner
+	if parent == "" {
+		parent = r.originalBranch
+	}
+
+	return r.gitClient.WriteLineage(&git.LineageRecord{
+		RunID:     r.runID,
+		Branch:    branch,
+		Commit:    commit,
+		Parents:   []string{parent},
+		Round:     r.round,
+		Verdict:   git.VerdictIncomplete,
+		CreatedAt: time.Now(),
+	})
+}
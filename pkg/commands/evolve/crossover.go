@@ -0,0 +1,101 @@
+package evolve
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+// maxCrossoverDiffBytes caps each parent diff embedded in the crossover prompt
+const maxCrossoverDiffBytes = 40000
+
+var crossoverPromptTemplate = `%s
+This branch starts from candidate %s. Candidate %s took a different approach.
+Combine the strengths of both candidates into a single implementation on this branch.
+
+Changes made by %s:
+%s
+
+Changes made by %s:
+%s`
+
+// crossoverRound merges ideas from the current winner and the runner-up into a
+// new branch, which then challenges the winner like any other challenger
+func (r *EvolutionRunner) crossoverRound(roundNum int, runnerUp string) error {
+	winner := r.currentWinner
+
+	winnerDiff, err := r.gitClient.Diff(r.originalBranch, winner)
+	if err != nil {
+		return err
+	}
+	runnerUpDiff, err := r.gitClient.Diff(r.originalBranch, runnerUp)
+	if err != nil {
+		return err
+	}
+
+	child := git.RandomBranchName()
+	if err := r.gitClient.CreateBranchFrom(child, winner); err != nil {
+		return err
+	}
+	r.pendingBranch = child
+
+	r.emitter.Emit(events.EventCrossoverStarted, events.CrossoverStartedData{
+		BranchName: child,
+		Parent1:    winner,
+		Parent2:    runnerUp,
+	})
+
+	prompt := fmt.Sprintf(crossoverPromptTemplate,
+		r.config.CrossoverPrompt, winner, runnerUp,
+		winner, truncateDiff(winnerDiff, maxCrossoverDiffBytes),
+		runnerUp, truncateDiff(runnerUpDiff, maxCrossoverDiffBytes))
+
+	opts := r.promptOptions(r.config.ImproveSystemPrompt, r.config.ImproveAppendSystemPrompt)
+	result, err := r.runPrompt(prompt, opts, r.emitter)
+	if err != nil {
+		return r.failChallenger(child, roundNum, err)
+	}
+
+	if err := r.commitCandidate(commitInfo{
+		Branch:  child,
+		Prompt:  r.config.CrossoverPrompt,
+		Round:   roundNum,
+		Parents: []string{winner, runnerUp},
+		Summary: result.Text,
+		CostUSD: result.CostUSD,
+	}); err != nil {
+		return err
+	}
+
+	loser, err := r.compareAndUpdate(child)
+	if err != nil {
+		return err
+	}
+
+	return r.discardBranch(loser)
+}
+
+// truncateDiff limits a diff to maxBytes so the prompt stays within argument
+// limits. It cuts after the last whole line that fits, or at a rune boundary
+// when the first line alone is too long.
+func truncateDiff(diff string, maxBytes int) string {
+	if len(diff) <= maxBytes {
+		return diff
+	}
+	cut := strings.LastIndexByte(diff[:maxBytes], '\n') + 1
+	if cut == 0 {
+		cut = maxBytes
+		for cut > 0 && !utf8.RuneStart(diff[cut]) {
+			cut--
+		}
+	}
+
+	truncated := diff[:cut]
+	if !strings.HasSuffix(truncated, "\n") {
+		truncated += "\n"
+	}
+	return truncated + fmt.Sprintf("... (%d more bytes truncated)", len(diff)-cut)
+}
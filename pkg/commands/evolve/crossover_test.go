@@ -0,0 +1,78 @@
+package evolve
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+func TestTruncateDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		maxBytes int
+		want     string
+	}{
+		{name: "fits", diff: "+a\n+b\n", maxBytes: 6, want: "+a\n+b\n"},
+		{name: "cut at line", diff: "+a\n+bcd\n+e\n", maxBytes: 6, want: "+a\n... (8 more bytes truncated)"},
+		{name: "cut at rune", diff: "+héllo", maxBytes: 3, want: "+h\n... (5 more bytes truncated)"},
+		{name: "multibyte line end", diff: "+é\n+ü\n", maxBytes: 5, want: "+é\n... (4 more bytes truncated)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateDiff(tt.diff, tt.maxBytes)
+			if got != tt.want {
+				t.Errorf("truncateDiff(%q, %d) = %q; want %q", tt.diff, tt.maxBytes, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateDiff(%q, %d) = %q; want valid UTF-8", tt.diff, tt.maxBytes, got)
+			}
+		})
+	}
+}
+
+func TestEvolve_CrossoverPrompt(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	stub := &stubClaude{repo: repo}
+	runner := newTestRunner(EvolveConfig{
+		Prompt:          "build it",
+		Iterations:      1,
+		Crossover:       true,
+		CrossoverPrompt: "combine them",
+	}, stub)
+
+	if err := runner.run(); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	var prompt string
+	for _, p := range stub.prompts {
+		if strings.HasPrefix(p, "combine them\n") {
+			prompt = p
+		}
+	}
+	if prompt == "" {
+		t.Fatalf("no crossover prompt in %q", stub.prompts)
+	}
+
+	var parents []string
+	for _, record := range runner.lineage {
+		if len(record.Parents) == 2 {
+			parents = record.Parents
+			if !strings.Contains(prompt, "This branch starts from candidate "+parents[0]) {
+				t.Errorf("prompt does not start from %s:\n%s", parents[0], prompt)
+			}
+		}
+	}
+	if len(parents) != 2 {
+		t.Fatalf("no crossover candidate in the lineage")
+	}
+	for _, parent := range parents {
+		if !strings.Contains(prompt, "Changes made by "+parent+":\ncommit ") {
+			t.Errorf("prompt missing the diff of %s:\n%s", parent, prompt)
+		}
+	}
+}
@@ -16,12 +16,23 @@ import (
 // EvolveConfig holds configuration for the evolution process
 type EvolveConfig struct {
 	Prompt              string        // Initial implementation prompt
-	ImprovePrompt       string        // Prompt for improvement step
+	ImprovePrompts      []string      // Prompts for improvement step, one chosen per round
+	PromptSchedule      string        // How improvement prompts are chosen: "cycle" or "random"
 	ComparePrompt       string        // Prompt for comparison step
 	Iterations          int           // Number of evolution iterations
 	Sleep               time.Duration // Sleep duration between evolution rounds
 	CompareErrorRetries int           // Number of retries when comparison parsing fails
 	DebugKeepBranches   bool          // Debug mode: keep all branches instead of deleting losers
+	Crossover           bool          // Run a crossover step combining the top two candidates each round
+	Stash               bool          // Stash uncommitted changes before the run and restore them afterwards
+	Isolate             bool          // Run in a worktree under .agent-exec/worktrees, leaving the user's checkout untouched
+	OnExit              string        // Branch to check out when the run ends: "winner" or "original"
+	KeepIncomplete      bool          // Keep a half-built challenger as "<branch>-incomplete" instead of deleting it
+	Finish              string        // How to integrate the winner: "merge", "squash", "rebase", "pr-branch" or empty
+	FinishBranch        string        // Branch name for the "pr-branch" finish mode (default "agent-exec/<run-id>")
+	SummarizeCommits    bool          // Ask Claude for a summary line for each candidate commit
+	RunID               string        // Identifier recorded in commit trailers (generated when empty)
+	CrossoverPrompt     string        // Prompt for crossover step
 
 	// System prompts for each step
 	SystemPrompt       string
@@ -32,42 +43,145 @@ type EvolveConfig struct {
 
 	CompareSystemPrompt       string
 	CompareAppendSystemPrompt string
+
+	// Record or replay claude invocations (nil = run the CLI)
+	Cassette *claude.Cassette
+	// Spending limit for the whole run (nil = unlimited)
+	Budget *claude.Budget
+	// Pauses, aborts or skips sleeps of the run from outside (nil = never)
+	Control *claude.Control
+	// Builds the commit message for the "squash" finish mode (nil = the winner's message)
+	SquashMessage func() (string, error)
+	// Masks secrets in commit messages and lineage notes (nil = stored as is)
+	Redact func(string) string
 }
 
+// promptRunner runs a single Claude prompt; claude.RunPrompt in production
+type promptRunner func(prompt string, opts *claude.PromptOptions, emitter events.Emitter) (claude.Result, error)
+
 // EvolutionRunner holds state for the evolution process
 type EvolutionRunner struct {
 	config         EvolveConfig
-	gitClient      *git.Client
+	gitClient      git.Repo
+	runPrompt      promptRunner
 	emitter        events.Emitter
 	originalBranch string
+	baseRef        string // What to check out between candidates: originalBranch, or its commit when isolated
 	currentWinner  string
+	runID          string
+	stash          string // Stash commit holding the user's uncommitted changes
+	pendingBranch  string // Candidate branch created but not yet committed
+	round          int    // Current round, 0 while building the initial implementation
+	finished       bool   // The winner was integrated with the configured finish mode
+	failed         int    // Challengers discarded after a failed Claude run
+	lineage        map[string]*git.LineageRecord
 	sigChan        chan os.Signal
 }
 
+// FailedChallengersError reports an evolve run that completed with a winner
+// although some challengers failed and were discarded
+type FailedChallengersError struct {
+	Failed int
+	Winner string
+}
+
+func (e *FailedChallengersError) Error() string {
+	return fmt.Sprintf("%d challenger(s) failed; the winner is %s", e.Failed, e.Winner)
+}
+
 // Evolve runs the evolutionary code improvement loop
 func Evolve(cfg EvolveConfig, emitter events.Emitter) error {
+	if err := validateSchedule(cfg.ImprovePrompts, cfg.PromptSchedule); err != nil {
+		return err
+	}
+	if err := validateOnExit(cfg.OnExit); err != nil {
+		return err
+	}
+	if err := validateFinish(cfg.Finish); err != nil {
+		return err
+	}
+	if err := validateSquashMessage(cfg); err != nil {
+		return err
+	}
+	if err := validateStash(cfg); err != nil {
+		return err
+	}
+	if err := validateIsolate(cfg); err != nil {
+		return err
+	}
+
+	return newEvolutionRunner(cfg, git.NewClient(emitter), claude.RunPrompt, emitter).run()
+}
+
+// newEvolutionRunner creates a runner operating on repo and running prompts with runPrompt
+func newEvolutionRunner(cfg EvolveConfig, repo git.Repo, runPrompt promptRunner, emitter events.Emitter) *EvolutionRunner {
 	runner := &EvolutionRunner{
-		config:  cfg,
-		emitter: emitter,
+		config:    cfg,
+		gitClient: repo,
+		runPrompt: runPrompt,
+		emitter:   emitter,
+		runID:     cfg.RunID,
 	}
-	return runner.run()
+	if runner.runID == "" {
+		runner.runID = events.NewRunID()
+	}
+	return runner
 }
 
-// run orchestrates the entire evolution process
-func (r *EvolutionRunner) run() error {
+// run orchestrates the entire evolution process. A run that completes after
+// discarding failed challengers returns a *FailedChallengersError.
+func (r *EvolutionRunner) run() (err error) {
 	r.setupSignals()
 	defer signal.Stop(r.sigChan)
 
-	r.gitClient = git.NewClient(r.emitter)
+	// Runs last, so cleanup still reports the run as completed
+	defer func() {
+		if err == nil && r.failed > 0 && r.currentWinner != "" {
+			err = &FailedChallengersError{Failed: r.failed, Winner: r.currentWinner}
+		}
+	}()
+
+	// An isolated run never touches the user's checkout, so uncommitted changes are fine
+	if err := r.gitClient.Preflight(r.config.Stash || r.config.Isolate); err != nil {
+		return fmt.Errorf("pre-flight check failed: %w", err)
+	}
 
-	var err error
 	r.originalBranch, err = r.gitClient.GetCurrentBranch()
 	if err != nil {
 		return err
 	}
+	r.baseRef = r.originalBranch
+
+	if r.config.Isolate {
+		mainRepo := r.gitClient
+		if err := r.isolate(); err != nil {
+			return err
+		}
+		defer func() {
+			removeErr := mainRepo.RemoveWorktree(r.gitClient.Dir())
+			if removeErr == nil {
+				removeErr = mainRepo.DeleteRun(r.runID)
+			}
+			if removeErr != nil && err == nil {
+				err = removeErr
+			}
+		}()
+	}
+
+	if r.config.Stash {
+		r.stash, err = r.gitClient.StashPush("agent-exec: evolve run " + r.runID)
+		if err != nil {
+			return err
+		}
+	}
+
+	defer func() {
+		err = r.cleanup(err)
+	}()
 
 	r.emitter.Emit(events.EventEvolveStarted, events.EvolveStartedData{
 		TotalIterations: r.config.Iterations,
+		RunID:           r.runID,
 	})
 
 	evolveStartTime := time.Now()
@@ -91,6 +205,7 @@ func (r *EvolutionRunner) run() error {
 			return err
 		}
 
+		r.round = i
 		r.emitter.Emit(events.EventRoundStarted, events.RoundStartedData{
 			Round: i,
 			Total: r.config.Iterations,
@@ -101,8 +216,11 @@ func (r *EvolutionRunner) run() error {
 			return err
 		}
 
-		if err := r.compareAndUpdate(challenger); err != nil {
-			return err
+		// A failed challenger was discarded; the winner stands this round
+		if challenger != "" {
+			if err := r.contest(i, challenger); err != nil {
+				return err
+			}
 		}
 
 		if i < r.config.Iterations && r.config.Sleep > 0 {
@@ -112,6 +230,15 @@ func (r *EvolutionRunner) run() error {
 		}
 	}
 
+	if err := r.recordFinal(); err != nil {
+		return err
+	}
+
+	if err := r.finish(); err != nil {
+		return fmt.Errorf("failed to finish with %s: %w", r.config.Finish, err)
+	}
+	r.finished = true
+
 	r.emitter.Emit(events.EventEvolveCompleted, events.EvolveCompletedData{
 		FinalBranch:   r.currentWinner,
 		TotalRounds:   r.config.Iterations,
@@ -121,24 +248,61 @@ func (r *EvolutionRunner) run() error {
 	return nil
 }
 
+// restoreStash restores the user's stashed changes once the original branch
+// is checked out again. Anywhere else it leaves them stashed, since popping
+// would carry them onto another branch.
+func (r *EvolutionRunner) restoreStash(checkedOut string) error {
+	if r.stash == "" {
+		return nil
+	}
+	if checkedOut != r.originalBranch {
+		return fmt.Errorf("your changes are still stashed as %s: check out %s and run 'git stash pop'", r.stash, r.originalBranch)
+	}
+	if err := r.gitClient.StashPop(r.stash); err != nil {
+		return err
+	}
+	r.stash = ""
+	return nil
+}
+
+// redact masks secrets in text stored in the repository, which may be pushed
+func (r *EvolutionRunner) redact(text string) string {
+	if r.config.Redact == nil {
+		return text
+	}
+	return r.config.Redact(text)
+}
+
+// promptOptions builds the options for a Claude step, running it in the run's working tree
+func (r *EvolutionRunner) promptOptions(systemPrompt, appendSystemPrompt string) *claude.PromptOptions {
+	return &claude.PromptOptions{
+		SystemPrompt:       systemPrompt,
+		AppendSystemPrompt: appendSystemPrompt,
+		Dir:                r.gitClient.Dir(),
+		Cassette:           r.config.Cassette,
+		Budget:             r.config.Budget,
+		Control:            r.config.Control,
+	}
+}
+
 // setupSignals configures signal handling for graceful shutdown
 func (r *EvolutionRunner) setupSignals() {
 	r.sigChan = make(chan os.Signal, 1)
 	signal.Notify(r.sigChan, syscall.SIGINT, syscall.SIGTERM)
 }
 
-// checkInterrupted checks if an interrupt signal was received
+// checkInterrupted checks if an interrupt signal was received or the run was aborted
 func (r *EvolutionRunner) checkInterrupted() error {
 	select {
 	case <-r.sigChan:
-		return fmt.Errorf("interrupted")
+		return claude.ErrInterrupted
+	case <-r.config.Control.Aborted():
+		return claude.ErrInterrupted
 	default:
 		return nil
 	}
 }
 
-const gitCommitMessage = "finished"
-
 // executeInitialPrompt creates and runs the initial implementation
 func (r *EvolutionRunner) executeInitialPrompt() error {
 	branchA := git.RandomBranchName()
@@ -146,16 +310,22 @@ func (r *EvolutionRunner) executeInitialPrompt() error {
 	if err := r.gitClient.CreateBranch(branchA); err != nil {
 		return err
 	}
+	r.pendingBranch = branchA
 
-	opts := &claude.PromptOptions{
-		SystemPrompt:       r.config.SystemPrompt,
-		AppendSystemPrompt: r.config.AppendSystemPrompt,
-	}
-	if _, err := claude.RunPrompt(r.config.Prompt, opts, r.emitter); err != nil {
+	opts := r.promptOptions(r.config.SystemPrompt, r.config.AppendSystemPrompt)
+	result, err := r.runPrompt(r.config.Prompt, opts, r.emitter)
+	if err != nil {
 		return err
 	}
 
-	if err := r.gitClient.SquashCommits(r.originalBranch, gitCommitMessage); err != nil {
+	if err := r.commitCandidate(commitInfo{
+		Branch:  branchA,
+		Prompt:  r.config.Prompt,
+		Round:   0,
+		Parents: []string{r.originalBranch},
+		Summary: result.Text,
+		CostUSD: result.CostUSD,
+	}); err != nil {
 		return err
 	}
 
@@ -163,27 +333,54 @@ func (r *EvolutionRunner) executeInitialPrompt() error {
 	return nil
 }
 
-// improveWinner creates an improvement branch and runs the improvement prompt
+// contest lets a challenger compete with the winner, optionally followed by a
+// crossover of the two, and deletes the loser
+func (r *EvolutionRunner) contest(roundNum int, challenger string) error {
+	loser, err := r.compareAndUpdate(challenger)
+	if err != nil {
+		return err
+	}
+
+	if r.config.Crossover {
+		if err := r.crossoverRound(roundNum, loser); err != nil {
+			return err
+		}
+	}
+
+	return r.discardBranch(loser)
+}
+
+// improveWinner creates an improvement branch and runs the improvement prompt.
+// It returns an empty branch name when the challenger failed and was discarded.
 func (r *EvolutionRunner) improveWinner(roundNum int) (string, error) {
 	challenger := git.RandomBranchName()
 
 	if err := r.gitClient.CreateBranchFrom(challenger, r.currentWinner); err != nil {
 		return "", err
 	}
+	r.pendingBranch = challenger
+
+	improvePrompt := r.improvePromptFor(roundNum)
 
 	r.emitter.Emit(events.EventImprovementStarted, events.ImprovementStartedData{
 		BranchName: challenger,
+		Prompt:     improvePrompt,
 	})
 
-	improveOpts := &claude.PromptOptions{
-		SystemPrompt:       r.config.ImproveSystemPrompt,
-		AppendSystemPrompt: r.config.ImproveAppendSystemPrompt,
-	}
-	if _, err := claude.RunPrompt(r.config.ImprovePrompt, improveOpts, r.emitter); err != nil {
-		return "", err
+	improveOpts := r.promptOptions(r.config.ImproveSystemPrompt, r.config.ImproveAppendSystemPrompt)
+	result, err := r.runPrompt(improvePrompt, improveOpts, r.emitter)
+	if err != nil {
+		return "", r.failChallenger(challenger, roundNum, err)
 	}
 
-	if err := r.gitClient.SquashCommits(r.originalBranch, gitCommitMessage); err != nil {
+	if err := r.commitCandidate(commitInfo{
+		Branch:  challenger,
+		Prompt:  improvePrompt,
+		Round:   roundNum,
+		Parents: []string{r.currentWinner},
+		Summary: result.Text,
+		CostUSD: result.CostUSD,
+	}); err != nil {
 		return "", err
 	}
 
@@ -196,8 +393,8 @@ Branch names to compare:
 - %s
 Respond with ONLY the branch name that should be DELETED (the worse one).`
 
-// compareAndUpdate compares branches and updates the winner
-func (r *EvolutionRunner) compareAndUpdate(challenger string) error {
+// compareAndUpdate compares branches, updates the winner and returns the loser
+func (r *EvolutionRunner) compareAndUpdate(challenger string) (string, error) {
 	r.emitter.Emit(events.EventComparisonStarted, events.ComparisonStartedData{
 		Branch1: r.currentWinner,
 		Branch2: challenger,
@@ -206,16 +403,13 @@ func (r *EvolutionRunner) compareAndUpdate(challenger string) error {
 	comparePrompt := fmt.Sprintf(comparePromptTemplate,
 		r.config.ComparePrompt, r.currentWinner, challenger)
 
-	if err := r.gitClient.Checkout(r.originalBranch); err != nil {
-		return err
+	if err := r.gitClient.Checkout(r.baseRef); err != nil {
+		return "", err
 	}
 
-	compareOpts := &claude.PromptOptions{
-		SystemPrompt:       r.config.CompareSystemPrompt,
-		AppendSystemPrompt: r.config.CompareAppendSystemPrompt,
-	}
+	compareOpts := r.promptOptions(r.config.CompareSystemPrompt, r.config.CompareAppendSystemPrompt)
 
-	var loser string
+	var loser, judge string
 	var err error
 	for attempt := 0; attempt <= r.config.CompareErrorRetries; attempt++ {
 		if attempt > 0 {
@@ -225,18 +419,19 @@ func (r *EvolutionRunner) compareAndUpdate(challenger string) error {
 			})
 		}
 
-		result, runErr := claude.RunPrompt(comparePrompt, compareOpts, r.emitter)
+		result, runErr := r.runPrompt(comparePrompt, compareOpts, r.emitter)
 		if runErr != nil {
-			return runErr
+			return "", runErr
 		}
 
-		loser, err = parseBranchFromResponse(result, r.currentWinner, challenger)
+		judge = result.Text
+		loser, err = parseBranchFromResponse(result.Text, r.currentWinner, challenger)
 		if err == nil {
 			break
 		}
 
 		if attempt == r.config.CompareErrorRetries {
-			return fmt.Errorf("failed to parse comparison result after %d retries: %w", r.config.CompareErrorRetries, err)
+			return "", fmt.Errorf("failed to parse comparison result after %d retries: %w", r.config.CompareErrorRetries, err)
 		}
 	}
 
@@ -247,40 +442,52 @@ func (r *EvolutionRunner) compareAndUpdate(challenger string) error {
 	r.emitter.Emit(events.EventWinnerSelected, events.WinnerSelectedData{
 		Winner: r.currentWinner,
 		Loser:  loser,
+		Judge:  strings.TrimSpace(judge),
 	})
 
-	if err := r.gitClient.Checkout(r.currentWinner); err != nil {
-		return err
+	if err := r.recordVerdict(r.currentWinner, loser, judge); err != nil {
+		return "", err
 	}
 
-	if !r.config.DebugKeepBranches {
-		if err := r.gitClient.DeleteBranch(loser); err != nil {
-			return err
-		}
+	if err := r.gitClient.Checkout(r.currentWinner); err != nil {
+		return "", err
 	}
 
-	return nil
+	return loser, nil
+}
+
+// discardBranch deletes an eliminated branch unless branches are kept for debugging
+func (r *EvolutionRunner) discardBranch(branch string) error {
+	if r.config.DebugKeepBranches {
+		return nil
+	}
+	return r.gitClient.DeleteBranch(branch)
 }
 
-// waitBetweenRounds implements interruptible sleep between evolution rounds
+// waitBetweenRounds implements interruptible sleep between evolution rounds,
+// which the run's Control can cut short
 func (r *EvolutionRunner) waitBetweenRounds(completedRound int) error {
 	r.emitter.Emit(events.EventSleepStarted, events.SleepStartedData{
 		Duration: r.config.Sleep,
 	})
 
 	timer := time.NewTimer(r.config.Sleep)
+	defer timer.Stop()
 	select {
 	case <-r.sigChan:
-		timer.Stop()
-		r.emitter.Emit(events.EventEvolveInterrupted, events.EvolveInterruptedData{
-			CompletedRounds: completedRound,
-			TotalRounds:     r.config.Iterations,
-			Winner:          r.currentWinner,
-		})
-		return fmt.Errorf("interrupted")
+	case <-r.config.Control.Aborted():
+	case <-r.config.Control.Skipped():
+		return nil
 	case <-timer.C:
 		return nil
 	}
+
+	r.emitter.Emit(events.EventEvolveInterrupted, events.EvolveInterruptedData{
+		CompletedRounds: completedRound,
+		TotalRounds:     r.config.Iterations,
+		Winner:          r.currentWinner,
+	})
+	return claude.ErrInterrupted
 }
 
 // parseBranchFromResponse extracts the loser branch name from Claude's response
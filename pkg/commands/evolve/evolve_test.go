@@ -0,0 +1,474 @@
+package evolve
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude"
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+// stubClaude stands in for the claude CLI. Implementation prompts leave
+// uncommitted changes in the fake repo; comparison prompts return a loser
+// chosen by the test.
+type stubClaude struct {
+	repo          *git.FakeRepo
+	keepIncumbent bool   // delete the challenger instead of the current winner
+	failOn        string // prompts containing this text return an error
+	failWith      error  // error returned for failOn (default: a CLI failure)
+	prompts       []string
+	dirs          []string // Working directory of each prompt
+}
+
+func (s *stubClaude) run(prompt string, opts *claude.PromptOptions, emitter events.Emitter) (claude.Result, error) {
+	s.prompts = append(s.prompts, prompt)
+	s.dirs = append(s.dirs, opts.Dir)
+	if s.failOn != "" && strings.Contains(prompt, s.failOn) {
+		if s.failWith != nil {
+			return claude.Result{}, s.failWith
+		}
+		return claude.Result{}, errors.New("claude exited with status 1")
+	}
+
+	if _, branches, ok := strings.Cut(prompt, "Branch names to compare:\n"); ok {
+		lines := strings.Split(branches, "\n")
+		incumbent := strings.TrimPrefix(lines[0], "- ")
+		challenger := strings.TrimPrefix(lines[1], "- ")
+		if s.keepIncumbent {
+			return claude.Result{Text: challenger}, nil
+		}
+		return claude.Result{Text: incumbent}, nil
+	}
+
+	s.repo.SetDirty("main.go")
+	return claude.Result{Text: "done", CostUSD: 0.01}, nil
+}
+
+func newTestRunner(cfg EvolveConfig, stub *stubClaude) *EvolutionRunner {
+	cfg.RunID = "test-run"
+	if len(cfg.ImprovePrompts) == 0 {
+		cfg.ImprovePrompts = []string{"improve it"}
+	}
+	return newEvolutionRunner(cfg, stub.repo, stub.run, events.NewNullEmitter())
+}
+
+// candidateBranches returns the branches other than main
+func candidateBranches(t *testing.T, repo *git.FakeRepo) []string {
+	t.Helper()
+	branches, err := repo.ListBranches()
+	if err != nil {
+		t.Fatalf("ListBranches failed: %v", err)
+	}
+	var names []string
+	for name := range branches {
+		if name != "main" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func TestEvolve_Tournament(t *testing.T) {
+	tests := []struct {
+		name          string
+		keepIncumbent bool
+		crossover     bool
+		wantPrompts   int
+		wantFitness   int
+	}{
+		{name: "challengers win", wantPrompts: 5, wantFitness: 1},
+		{name: "incumbent wins", keepIncumbent: true, wantPrompts: 5, wantFitness: 2},
+		{name: "with crossover", crossover: true, wantPrompts: 9, wantFitness: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := git.NewFakeRepo("main")
+			stub := &stubClaude{repo: repo, keepIncumbent: tt.keepIncumbent}
+			runner := newTestRunner(EvolveConfig{
+				Prompt:     "build it",
+				Iterations: 2,
+				Crossover:  tt.crossover,
+			}, stub)
+
+			if err := runner.run(); err != nil {
+				t.Fatalf("run failed: %v", err)
+			}
+
+			if len(stub.prompts) != tt.wantPrompts {
+				t.Errorf("got %d prompts; want %d", len(stub.prompts), tt.wantPrompts)
+			}
+
+			remaining := candidateBranches(t, repo)
+			if len(remaining) != 1 || remaining[0] != runner.currentWinner {
+				t.Errorf("got branches %v; want only winner %s", remaining, runner.currentWinner)
+			}
+
+			current, _ := repo.GetCurrentBranch()
+			if current != runner.currentWinner {
+				t.Errorf("got checked out %s; want winner %s", current, runner.currentWinner)
+			}
+
+			record := runner.lineage[runner.currentWinner]
+			if record == nil {
+				t.Fatalf("no lineage record for winner %s", runner.currentWinner)
+			}
+			if !record.Final || record.Verdict != git.VerdictWon {
+				t.Errorf("got final=%v verdict=%s; want final winner", record.Final, record.Verdict)
+			}
+			if record.Fitness != tt.wantFitness {
+				t.Errorf("got fitness %d; want %d", record.Fitness, tt.wantFitness)
+			}
+		})
+	}
+}
+
+func TestEvolve_FailedChallengerIsCleanedUp(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	stub := &stubClaude{repo: repo, failOn: "improve it"}
+	runner := newTestRunner(EvolveConfig{Prompt: "build it", Iterations: 1}, stub)
+
+	if err := runner.run(); err == nil {
+		t.Fatal("run succeeded; want error from failed challenger")
+	}
+
+	remaining := candidateBranches(t, repo)
+	if len(remaining) != 1 || remaining[0] != runner.currentWinner {
+		t.Errorf("got branches %v; want only winner %s", remaining, runner.currentWinner)
+	}
+
+	dirty, _ := repo.DirtyPaths()
+	if len(dirty) != 0 {
+		t.Errorf("got dirty paths %v; want clean tree", dirty)
+	}
+}
+
+func TestEvolve_ErrorResultFailsChallenger(t *testing.T) {
+	tests := []struct {
+		name           string
+		keepIncomplete bool
+		failOn         string
+		wantKept       int
+		wantFailed     int
+	}{
+		{name: "improvement deleted", failOn: "improve it", wantFailed: 2},
+		{name: "improvement kept", failOn: "improve it", keepIncomplete: true, wantKept: 2, wantFailed: 2},
+		{name: "crossover deleted", failOn: "combine", wantFailed: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := git.NewFakeRepo("main")
+			stub := &stubClaude{
+				repo:     repo,
+				failOn:   tt.failOn,
+				failWith: &claude.ResultError{Kind: claude.ErrorMaxTurns, NumTurns: 30},
+			}
+			runner := newTestRunner(EvolveConfig{
+				Prompt:          "build it",
+				Iterations:      2,
+				Crossover:       true,
+				CrossoverPrompt: "combine them",
+				KeepIncomplete:  tt.keepIncomplete,
+			}, stub)
+
+			// The run completes with the winner but reports the discarded challengers
+			var failedErr *FailedChallengersError
+			if err := runner.run(); !errors.As(err, &failedErr) || failedErr.Failed != tt.wantFailed || failedErr.Winner != runner.currentWinner {
+				t.Fatalf("run() error = %v; want %d failed challengers with winner %s", err, tt.wantFailed, runner.currentWinner)
+			}
+
+			var kept int
+			for _, name := range candidateBranches(t, repo) {
+				if strings.HasSuffix(name, incompleteSuffix) {
+					kept++
+				} else if name != runner.currentWinner {
+					t.Errorf("leftover branch %s", name)
+				}
+			}
+			if kept != tt.wantKept {
+				t.Errorf("got %d incomplete branches; want %d", kept, tt.wantKept)
+			}
+
+			current, _ := repo.GetCurrentBranch()
+			if current != runner.currentWinner {
+				t.Errorf("got checked out %s; want winner %s", current, runner.currentWinner)
+			}
+		})
+	}
+}
+
+func TestEvolve_WrappedInterruptIsReported(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	stub := &stubClaude{repo: repo, failOn: "improve it", failWith: fmt.Errorf("improvement failed: %w", claude.ErrAborted)}
+	emitter := events.NewChannelEmitter(1000)
+	runner := newTestRunner(EvolveConfig{Prompt: "build it", Iterations: 1}, stub)
+	runner.emitter = emitter
+
+	err := runner.run()
+	emitter.Close()
+	if !errors.Is(err, claude.ErrInterrupted) {
+		t.Fatalf("run error = %v; want ErrInterrupted", err)
+	}
+
+	var cleanup events.EvolveCleanupData
+	for event := range emitter.Subscribe() {
+		if event.Type == events.EventEvolveCleanup {
+			cleanup = event.Data.(events.EvolveCleanupData)
+		}
+	}
+	if cleanup.Outcome != "interrupted" {
+		t.Errorf("got cleanup outcome %q; want interrupted", cleanup.Outcome)
+	}
+}
+
+func TestEvolve_KeepIncomplete(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	stub := &stubClaude{repo: repo, failOn: "improve it"}
+	runner := newTestRunner(EvolveConfig{
+		Prompt:         "build it",
+		Iterations:     1,
+		KeepIncomplete: true,
+		OnExit:         OnExitOriginal,
+	}, stub)
+
+	if err := runner.run(); err == nil {
+		t.Fatal("run succeeded; want error from failed challenger")
+	}
+
+	var incomplete int
+	for _, name := range candidateBranches(t, repo) {
+		if strings.HasSuffix(name, incompleteSuffix) {
+			incomplete++
+		}
+	}
+	if incomplete != 1 {
+		t.Errorf("got %d incomplete branches; want 1", incomplete)
+	}
+
+	current, _ := repo.GetCurrentBranch()
+	if current != "main" {
+		t.Errorf("got checked out %s; want main", current)
+	}
+}
+
+func TestEvolve_DirtyTreeRejected(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	repo.SetDirty("notes.txt")
+	stub := &stubClaude{repo: repo}
+	runner := newTestRunner(EvolveConfig{Prompt: "build it", Iterations: 1}, stub)
+
+	if err := runner.run(); err == nil {
+		t.Fatal("run succeeded; want pre-flight error")
+	}
+	if len(stub.prompts) != 0 {
+		t.Errorf("got %d prompts; want none before pre-flight passes", len(stub.prompts))
+	}
+}
+
+func TestEvolve_StashRestoresChanges(t *testing.T) {
+	tests := []struct {
+		onExit    string
+		wantDirty bool
+		wantError string
+	}{
+		{onExit: OnExitOriginal, wantDirty: true},
+		// Popping on the winner would carry the changes onto it; they stay stashed
+		{onExit: OnExitWinner, wantError: "still stashed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.onExit, func(t *testing.T) {
+			repo := git.NewFakeRepo("main")
+			repo.SetDirty("notes.txt")
+			stub := &stubClaude{repo: repo}
+			emitter := events.NewChannelEmitter(1000)
+			runner := newTestRunner(EvolveConfig{Prompt: "build it", Iterations: 1, Stash: true, OnExit: tt.onExit}, stub)
+			runner.emitter = emitter
+
+			err := runner.run()
+			emitter.Close()
+			if (err != nil) != (tt.wantError != "") {
+				t.Fatalf("run() error = %v; want error %q", err, tt.wantError)
+			}
+
+			want := "main"
+			if tt.onExit == OnExitWinner {
+				want = runner.currentWinner
+			}
+			current, _ := repo.GetCurrentBranch()
+			dirty, _ := repo.DirtyPaths()
+			gotDirty := len(dirty) == 1 && dirty[0] == "notes.txt"
+			if current != want || gotDirty != tt.wantDirty || (!tt.wantDirty && len(dirty) > 0) {
+				t.Errorf("got branch %s with dirty %v; want %s with restored changes %v", current, dirty, want, tt.wantDirty)
+			}
+
+			var cleanup events.EvolveCleanupData
+			for event := range emitter.Subscribe() {
+				if event.Type == events.EventEvolveCleanup {
+					cleanup = event.Data.(events.EvolveCleanupData)
+				}
+			}
+			if cleanup.CheckedOut != want || !strings.Contains(cleanup.Error, tt.wantError) || (tt.wantError == "") != (cleanup.Error == "") {
+				t.Errorf("got cleanup %+v; want checked out %s with error %q", cleanup, want, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestValidateStash(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     EvolveConfig
+		wantErr bool
+	}{
+		{name: "no stash", cfg: EvolveConfig{OnExit: OnExitWinner}},
+		{name: "stash to original", cfg: EvolveConfig{Stash: true, OnExit: OnExitOriginal}},
+		{name: "stash to winner", cfg: EvolveConfig{Stash: true, OnExit: OnExitWinner}, wantErr: true},
+		{name: "stash with default exit", cfg: EvolveConfig{Stash: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStash(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateStash() error = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvolve_Finish(t *testing.T) {
+	tests := []struct {
+		finish     string
+		wantBranch string
+	}{
+		{finish: FinishMerge, wantBranch: "main"},
+		{finish: FinishSquash, wantBranch: "main"},
+		{finish: FinishRebase, wantBranch: "main"},
+		{finish: FinishPRBranch, wantBranch: "agent-exec/test-run"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.finish, func(t *testing.T) {
+			repo := git.NewFakeRepo("main")
+			before, _ := repo.ResolveCommit("main")
+			stub := &stubClaude{repo: repo}
+			runner := newTestRunner(EvolveConfig{Prompt: "build it", Iterations: 1, Finish: tt.finish}, stub)
+
+			if err := runner.run(); err != nil {
+				t.Fatalf("run failed: %v", err)
+			}
+
+			current, _ := repo.GetCurrentBranch()
+			if current != tt.wantBranch {
+				t.Errorf("got checked out %s; want %s", current, tt.wantBranch)
+			}
+
+			after, _ := repo.ResolveCommit("main")
+			moved := after != before
+			if moved != integratesIntoOriginal(tt.finish) {
+				t.Errorf("main moved = %v; want %v", moved, integratesIntoOriginal(tt.finish))
+			}
+		})
+	}
+}
+
+func TestEvolve_FinishSquashMessage(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	stub := &stubClaude{repo: repo}
+	cfg := EvolveConfig{Prompt: "build it", Iterations: 1, Finish: FinishSquash}
+	cfg.SquashMessage = func() (string, error) { return "Evolve: build it\n\n- Cost: $0\n", nil }
+	runner := newTestRunner(cfg, stub)
+
+	if err := runner.run(); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	message, _ := repo.CommitMessage("main")
+	want := "Evolve: build it\n\n- Cost: $0\n\n" + RunIDTrailer + ": test-run"
+	if message != want {
+		t.Errorf("got squash message %q; want %q", message, want)
+	}
+}
+
+func TestEvolve_RedactsStoredText(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	stub := &stubClaude{repo: repo}
+	cfg := EvolveConfig{Prompt: "build it with s3cret-token", Iterations: 1, Finish: FinishSquash}
+	cfg.SquashMessage = func() (string, error) { return "Evolve: build it with s3cret-token\n", nil }
+	cfg.Redact = func(text string) string { return strings.ReplaceAll(text, "s3cret-token", "<redacted>") }
+	runner := newTestRunner(cfg, stub)
+
+	if err := runner.run(); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	message, _ := repo.CommitMessage("main")
+	if strings.Contains(message, "s3cret-token") || !strings.Contains(message, "<redacted>") {
+		t.Errorf("squash message was not masked: %q", message)
+	}
+
+	records, err := repo.ReadLineage()
+	if err != nil || len(records) == 0 {
+		t.Fatalf("got %d lineage records (%v); want some", len(records), err)
+	}
+	for _, record := range records {
+		if strings.Contains(record.Prompt, "s3cret-token") {
+			t.Errorf("lineage note of %s was not masked: %q", record.Branch, record.Prompt)
+		}
+		if message, _ := repo.CommitMessage(record.Commit); strings.Contains(message, "s3cret-token") {
+			t.Errorf("commit message of %s was not masked: %q", record.Branch, message)
+		}
+	}
+}
+
+func TestEvolve_FinishConflict(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	stub := &stubClaude{repo: repo}
+	runner := newTestRunner(EvolveConfig{Prompt: "build it", Iterations: 1, Finish: FinishMerge}, stub)
+
+	// Every candidate conflicts, whichever wins
+	runner.runPrompt = func(prompt string, opts *claude.PromptOptions, emitter events.Emitter) (claude.Result, error) {
+		if current, _ := repo.GetCurrentBranch(); current != "main" {
+			repo.SetConflict(current)
+		}
+		return stub.run(prompt, opts, emitter)
+	}
+
+	err := runner.run()
+	var conflict *git.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("got error %v; want ConflictError", err)
+	}
+}
+
+func TestParseBranchFromResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+		wantErr  bool
+	}{
+		{name: "only first", response: "impl-aaa", want: "impl-aaa"},
+		{name: "only second", response: "Delete impl-bbb.", want: "impl-bbb"},
+		{name: "both, last line decides", response: "impl-aaa is better than impl-bbb\nimpl-bbb", want: "impl-bbb"},
+		{name: "neither", response: "I cannot decide", wantErr: true},
+		{name: "both, ambiguous", response: "impl-aaa or impl-bbb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBranchFromResponse(tt.response, "impl-aaa", "impl-bbb")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBranchFromResponse error = %v; wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package evolve
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+// Ways to integrate the winner when evolve completes
+const (
+	FinishNone     = ""
+	FinishMerge    = "merge"
+	FinishSquash   = "squash"
+	FinishRebase   = "rebase"
+	FinishPRBranch = "pr-branch"
+)
+
+// validateFinish checks the configured finish mode
+func validateFinish(finish string) error {
+	switch finish {
+	case FinishNone, FinishMerge, FinishSquash, FinishRebase, FinishPRBranch:
+		return nil
+	default:
+		return fmt.Errorf("unknown --finish mode: %s (expected %s, %s, %s or %s)",
+			finish, FinishMerge, FinishSquash, FinishRebase, FinishPRBranch)
+	}
+}
+
+// validateSquashMessage rejects a squash message without the squash finish mode
+func validateSquashMessage(cfg EvolveConfig) error {
+	if cfg.SquashMessage != nil && cfg.Finish != FinishSquash {
+		return fmt.Errorf("--summary-message only applies to --finish %s", FinishSquash)
+	}
+	return nil
+}
+
+// integratesIntoOriginal reports whether the finish mode moves the original branch
+func integratesIntoOriginal(finish string) bool {
+	return finish == FinishMerge || finish == FinishSquash || finish == FinishRebase
+}
+
+// finish integrates the winner into the original branch, or leaves it under a
+// review-ready name, and reports the resulting commit
+func (r *EvolutionRunner) finish() error {
+	mode := r.config.Finish
+	if mode == FinishNone {
+		return nil
+	}
+
+	winner := r.currentWinner
+	target := r.originalBranch
+
+	switch mode {
+	case FinishMerge:
+		if err := r.gitClient.Checkout(r.originalBranch); err != nil {
+			return err
+		}
+		message := fmt.Sprintf("Merge evolve winner %s\n\n%s: %s\n", winner, RunIDTrailer, r.runID)
+		if err := r.gitClient.Merge(winner, message); err != nil {
+			return err
+		}
+
+	case FinishSquash:
+		message, err := r.squashMessage(winner)
+		if err != nil {
+			return err
+		}
+		if err := r.gitClient.Checkout(r.originalBranch); err != nil {
+			return err
+		}
+		if err := r.gitClient.SquashMerge(winner, message); err != nil {
+			return err
+		}
+
+	case FinishRebase:
+		if err := r.gitClient.RebaseOnto(winner, r.originalBranch); err != nil {
+			return err
+		}
+		if err := r.gitClient.Checkout(r.originalBranch); err != nil {
+			return err
+		}
+		if err := r.gitClient.FastForward(winner); err != nil {
+			return err
+		}
+
+	case FinishPRBranch:
+		target = r.config.FinishBranch
+		if target == "" {
+			target = git.RunBranchPrefix + r.runID
+		}
+		if err := r.gitClient.RenameBranch(winner, target); err != nil {
+			return err
+		}
+		r.currentWinner = target
+		if record, ok := r.lineage[winner]; ok {
+			delete(r.lineage, winner)
+			record.Branch = target
+			r.lineage[target] = record
+			if err := r.gitClient.WriteLineage(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	commit, err := r.gitClient.ResolveCommit(target)
+	if err != nil {
+		return err
+	}
+
+	r.emitter.Emit(events.EventEvolveFinished, events.EvolveFinishedData{
+		Mode:   mode,
+		Winner: winner,
+		Branch: target,
+		Commit: commit,
+	})
+
+	return nil
+}
+
+// squashMessage returns the commit message for squashing winner: the
+// configured SquashMessage with the run trailer, or the winner's own message
+func (r *EvolutionRunner) squashMessage(winner string) (string, error) {
+	if r.config.SquashMessage == nil {
+		return r.gitClient.CommitMessage(winner)
+	}
+	message, err := r.config.SquashMessage()
+	if err != nil {
+		return "", fmt.Errorf("failed to build squash message: %w", err)
+	}
+	return fmt.Sprintf("%s\n\n%s: %s\n", strings.TrimRight(r.redact(message), "\n"), RunIDTrailer, r.runID), nil
+}
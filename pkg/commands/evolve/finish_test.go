@@ -0,0 +1,62 @@
+package evolve
+
+import "testing"
+
+func TestValidateFinish(t *testing.T) {
+	tests := []struct {
+		finish  string
+		wantErr bool
+	}{
+		{finish: FinishNone, wantErr: false},
+		{finish: FinishMerge, wantErr: false},
+		{finish: FinishSquash, wantErr: false},
+		{finish: FinishRebase, wantErr: false},
+		{finish: FinishPRBranch, wantErr: false},
+		{finish: "cherry-pick", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.finish, func(t *testing.T) {
+			err := validateFinish(tt.finish)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFinish(%q) error = %v; wantErr %v", tt.finish, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSquashMessage(t *testing.T) {
+	message := func() (string, error) { return "summary", nil }
+	tests := []struct {
+		name    string
+		cfg     EvolveConfig
+		wantErr bool
+	}{
+		{name: "no message", cfg: EvolveConfig{Finish: FinishMerge}},
+		{name: "squash", cfg: EvolveConfig{Finish: FinishSquash, SquashMessage: message}},
+		{name: "merge", cfg: EvolveConfig{Finish: FinishMerge, SquashMessage: message}, wantErr: true},
+		{name: "no finish", cfg: EvolveConfig{SquashMessage: message}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSquashMessage(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSquashMessage() error = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIntegratesIntoOriginal(t *testing.T) {
+	for _, mode := range []string{FinishMerge, FinishSquash, FinishRebase} {
+		if !integratesIntoOriginal(mode) {
+			t.Errorf("integratesIntoOriginal(%q) = false; want true", mode)
+		}
+	}
+	for _, mode := range []string{FinishNone, FinishPRBranch} {
+		if integratesIntoOriginal(mode) {
+			t.Errorf("integratesIntoOriginal(%q) = true; want false", mode)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package evolve
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude"
+	"github.com/LinHanLab/agent-exec/pkg/claude/claudetest"
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+func TestMain(m *testing.M) {
+	claudetest.Main()
+	os.Exit(m.Run())
+}
+
+// initRepo creates a git repository with one commit on main and makes it the working directory
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	runGit(t, "init", "--quiet", "--initial-branch", "main")
+	runGit(t, "config", "user.name", "agent-exec test")
+	runGit(t, "config", "user.email", "test@agent-exec.invalid")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, "add", "README.md")
+	runGit(t, "commit", "--quiet", "-m", "initial commit")
+	return dir
+}
+
+// runGit runs git in the working directory and returns its trimmed output
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %s", strings.Join(args, " "), output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// tournamentScript builds the initial implementation, writes v2 and v3 in the
+// two improvement rounds and always prefers the challenger
+func tournamentScript() claudetest.Script {
+	return claudetest.Script{Steps: []claudetest.Step{
+		{Match: "Branch names to compare", Verdict: claudetest.VerdictFirst},
+		{Match: "make it better", Call: 4, Files: map[string]string{"app.txt": "v3\n"}, Text: "v3"},
+		{Match: "make it better", Files: map[string]string{"app.txt": "v2\n"}, Text: "v2"},
+		{Match: "build the app", Files: map[string]string{"app.txt": "v1\n"}, Text: "v1"},
+	}}
+}
+
+func integrationConfig() EvolveConfig {
+	return EvolveConfig{
+		Prompt:         "build the app",
+		ImprovePrompts: []string{"make it better"},
+		ComparePrompt:  "judge these",
+		Iterations:     2,
+		RunID:          "it-run",
+	}
+}
+
+func TestEvolveIntegration_SquashFinish(t *testing.T) {
+	dir := initRepo(t)
+	fake := claudetest.Install(t, tournamentScript())
+
+	cfg := integrationConfig()
+	cfg.Finish = FinishSquash
+	if err := Evolve(cfg, events.NewNullEmitter()); err != nil {
+		t.Fatalf("Evolve failed: %v", err)
+	}
+
+	if calls := len(fake.Calls(t)); calls != 5 {
+		t.Errorf("got %d claude calls; want 5", calls)
+	}
+	if branch := runGit(t, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("got checked out %s; want main", branch)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "app.txt")); string(content) != "v3\n" {
+		t.Errorf("got app.txt %q on main; want the final winner's v3", content)
+	}
+	if message := runGit(t, "log", "-1", "--format=%B"); !strings.Contains(message, RunIDTrailer+": it-run") {
+		t.Errorf("got squash message %q; want run trailer", message)
+	}
+	if status := runGit(t, "status", "--porcelain"); status != "" {
+		t.Errorf("got dirty tree %q; want clean", status)
+	}
+
+	records, err := git.NewClient(events.NewNullEmitter()).ReadLineage()
+	if err != nil {
+		t.Fatalf("ReadLineage failed: %v", err)
+	}
+	var finals int
+	for _, record := range records {
+		if record.Final {
+			finals++
+		}
+	}
+	if len(records) != 3 || finals != 1 {
+		t.Errorf("got %d lineage records with %d final; want 3 with 1 final", len(records), finals)
+	}
+}
+
+func TestEvolveIntegration_FailedChallenger(t *testing.T) {
+	initRepo(t)
+	claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{
+		{Match: "make it better", ExitCode: 1},
+		{Match: "build the app", Files: map[string]string{"app.txt": "v1\n"}, Text: "v1"},
+	}})
+
+	if err := Evolve(integrationConfig(), events.NewNullEmitter()); err == nil {
+		t.Fatal("Evolve succeeded; want error from failed challenger")
+	}
+
+	branches := strings.Fields(runGit(t, "branch", "--format=%(refname:short)"))
+	if len(branches) != 2 {
+		t.Fatalf("got branches %v; want main and the initial winner", branches)
+	}
+	if branch := runGit(t, "rev-parse", "--abbrev-ref", "HEAD"); branch == "main" {
+		t.Error("got main checked out; want the winner")
+	}
+	if status := runGit(t, "status", "--porcelain"); status != "" {
+		t.Errorf("got dirty tree %q; want clean", status)
+	}
+}
+
+func TestEvolveIntegration_Isolate(t *testing.T) {
+	dir := initRepo(t)
+	fake := claudetest.Install(t, tournamentScript())
+	if err := os.WriteFile(filepath.Join(dir, "scratch.txt"), []byte("mine\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := integrationConfig()
+	cfg.Isolate = true
+	cfg.Finish = FinishPRBranch
+	if err := Evolve(cfg, events.NewNullEmitter()); err != nil {
+		t.Fatalf("Evolve failed: %v", err)
+	}
+
+	for _, call := range fake.Calls(t) {
+		if !strings.Contains(call.Dir, filepath.Join(git.StateDir, "worktrees", "it-run")) {
+			t.Errorf("claude ran in %s; want the run's worktree", call.Dir)
+		}
+	}
+	if branch := runGit(t, "rev-parse", "--abbrev-ref", "HEAD"); branch != "main" {
+		t.Errorf("got checked out %s; want main untouched", branch)
+	}
+	if status := runGit(t, "status", "--porcelain"); status != "?? scratch.txt" {
+		t.Errorf("got status %q; want only the user's scratch.txt", status)
+	}
+	if worktrees := runGit(t, "worktree", "list"); strings.Count(worktrees, "\n") != 0 {
+		t.Errorf("got worktrees %q; want the run's worktree removed", worktrees)
+	}
+	if content := runGit(t, "show", "agent-exec/it-run:app.txt"); content != "v3" {
+		t.Errorf("got app.txt %q on the review branch; want v3", content)
+	}
+	if _, err := os.Stat(filepath.Join(dir, git.RunsDir, "it-run", git.RunFile)); !os.IsNotExist(err) {
+		t.Errorf("got run record stat error %v; want the record deleted with the worktree", err)
+	}
+}
+
+func TestEvolveIntegration_RecordAndReplay(t *testing.T) {
+	cassetteDir := filepath.Join(t.TempDir(), "cassette")
+
+	initRepo(t)
+	claudetest.Install(t, tournamentScript())
+	recorder, err := claude.NewRecorder(cassetteDir)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	cfg := integrationConfig()
+	cfg.Cassette = recorder
+	if err := Evolve(cfg, events.NewNullEmitter()); err != nil {
+		t.Fatalf("recorded Evolve failed: %v", err)
+	}
+
+	// Replay in a fresh repository without a claude binary
+	initRepo(t)
+	t.Setenv(claude.BinaryEnv, filepath.Join(t.TempDir(), "missing-claude"))
+	replayer, err := claude.NewReplayer(cassetteDir)
+	if err != nil {
+		t.Fatalf("NewReplayer failed: %v", err)
+	}
+	cfg = integrationConfig()
+	cfg.Cassette = replayer
+	if err := Evolve(cfg, events.NewNullEmitter()); err != nil {
+		t.Fatalf("replayed Evolve failed: %v", err)
+	}
+
+	records, err := git.NewClient(events.NewNullEmitter()).ReadLineage()
+	if err != nil {
+		t.Fatalf("ReadLineage failed: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("got %d lineage records in the replayed run; want 3", len(records))
+	}
+}
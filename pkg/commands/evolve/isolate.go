@@ -0,0 +1,38 @@
+package evolve
+
+import (
+	"errors"
+	"fmt"
+)
+
+// validateIsolate rejects options that need the user's checkout when the run is isolated
+func validateIsolate(cfg EvolveConfig) error {
+	if !cfg.Isolate {
+		return nil
+	}
+	if cfg.Stash {
+		return errors.New("--stash is not needed with --isolate: uncommitted changes stay in your checkout")
+	}
+	if integratesIntoOriginal(cfg.Finish) {
+		return fmt.Errorf("--finish %s checks out the original branch and cannot be combined with --isolate; use --finish %s", cfg.Finish, FinishPRBranch)
+	}
+	return nil
+}
+
+// isolate moves the run into a new worktree detached at the original branch, so
+// candidate branches can be created and checked out without touching the user's
+// checkout, which keeps the original branch
+func (r *EvolutionRunner) isolate() error {
+	worktree, err := r.gitClient.AddWorktree(r.runID, r.originalBranch, "")
+	if err != nil {
+		return err
+	}
+
+	r.baseRef, err = worktree.ResolveCommit(r.originalBranch)
+	if err != nil {
+		return err
+	}
+
+	r.gitClient = worktree
+	return nil
+}
@@ -0,0 +1,58 @@
+package evolve
+
+import (
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+func TestValidateIsolate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     EvolveConfig
+		wantErr bool
+	}{
+		{name: "not isolated", cfg: EvolveConfig{Stash: true, Finish: FinishMerge}, wantErr: false},
+		{name: "isolated", cfg: EvolveConfig{Isolate: true}, wantErr: false},
+		{name: "isolated pr-branch", cfg: EvolveConfig{Isolate: true, Finish: FinishPRBranch}, wantErr: false},
+		{name: "isolated stash", cfg: EvolveConfig{Isolate: true, Stash: true}, wantErr: true},
+		{name: "isolated squash", cfg: EvolveConfig{Isolate: true, Finish: FinishSquash}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIsolate(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateIsolate() error = %v; wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvolve_Isolate(t *testing.T) {
+	repo := git.NewFakeRepo("main")
+	repo.SetDirty("notes.txt")
+	stub := &stubClaude{repo: repo}
+	runner := newTestRunner(EvolveConfig{Prompt: "build it", Iterations: 1, Isolate: true}, stub)
+
+	if err := runner.run(); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	want := repo.Dir() + "/.agent-exec/worktrees/test-run"
+	for i, dir := range stub.dirs {
+		if dir != want {
+			t.Errorf("prompt %d ran in %q; want %q", i, dir, want)
+		}
+	}
+
+	worktrees, _ := repo.ListWorktrees()
+	if len(worktrees) != 1 {
+		t.Errorf("got %d worktrees; want the worktree removed", len(worktrees))
+	}
+
+	remaining := candidateBranches(t, repo)
+	if len(remaining) != 1 || remaining[0] != runner.currentWinner {
+		t.Errorf("got branches %v; want only winner %s", remaining, runner.currentWinner)
+	}
+}
@@ -0,0 +1,69 @@
+package evolve
+
+import (
+	"strings"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+// recordCandidate writes the initial lineage note for a freshly committed candidate
+func (r *EvolutionRunner) recordCandidate(info commitInfo) error {
+	commit, err := r.gitClient.HeadCommit()
+	if err != nil {
+		return err
+	}
+
+	record := &git.LineageRecord{
+		RunID:     r.runID,
+		Branch:    info.Branch,
+		Commit:    commit,
+		Parents:   info.Parents,
+		Round:     info.Round,
+		Prompt:    r.redact(info.Prompt),
+		Verdict:   git.VerdictPending,
+		CostUSD:   info.CostUSD,
+		CreatedAt: time.Now(),
+	}
+
+	if r.lineage == nil {
+		r.lineage = make(map[string]*git.LineageRecord)
+	}
+	r.lineage[info.Branch] = record
+
+	return r.gitClient.WriteLineage(record)
+}
+
+// recordVerdict updates the lineage notes of both compared branches with the judge's verdict
+func (r *EvolutionRunner) recordVerdict(winner, loser, judge string) error {
+	judge = r.redact(strings.TrimSpace(judge))
+
+	if record, ok := r.lineage[winner]; ok {
+		record.Verdict = git.VerdictWon
+		record.Judge = judge
+		record.Fitness++
+		if err := r.gitClient.WriteLineage(record); err != nil {
+			return err
+		}
+	}
+
+	if record, ok := r.lineage[loser]; ok {
+		record.Verdict = git.VerdictLost
+		record.Judge = judge
+		if err := r.gitClient.WriteLineage(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordFinal marks the overall winner of the run in its lineage note
+func (r *EvolutionRunner) recordFinal() error {
+	record, ok := r.lineage[r.currentWinner]
+	if !ok {
+		return nil
+	}
+	record.Final = true
+	return r.gitClient.WriteLineage(record)
+}
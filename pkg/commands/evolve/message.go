@@ -0,0 +1,114 @@
+package evolve
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LinHanLab/agent-exec/pkg/display"
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+const (
+	// RunIDTrailer is the commit trailer linking a candidate commit to its run
+	RunIDTrailer = "Agent-Exec-Run-Id"
+
+	maxSubjectLen       = 72
+	maxPromptLineLen    = 200
+	summarizeCommitText = `Summarize the uncommitted changes in this repository as a single git commit subject line of at most 72 characters.
+Respond with ONLY the subject line.`
+)
+
+// commitInfo describes a candidate commit for message generation
+type commitInfo struct {
+	Branch  string
+	RunID   string
+	Prompt  string
+	Round   int // 0 for the initial implementation
+	Total   int
+	Parents []string
+	Summary string
+	CostUSD float64
+}
+
+// buildCommitMessage renders a squash commit message for a candidate branch
+func buildCommitMessage(info commitInfo) string {
+	subject := summarizeLine(info.Summary)
+	if subject == "" {
+		if info.Round == 0 {
+			subject = "Initial implementation"
+		} else {
+			subject = fmt.Sprintf("Evolve round %d/%d candidate", info.Round, info.Total)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(subject)
+	b.WriteString("\n\n")
+	fmt.Fprintf(&b, "Prompt: %s\n", display.Truncate(flattenLine(info.Prompt), maxPromptLineLen))
+	if info.Round == 0 {
+		b.WriteString("Round: initial\n")
+	} else {
+		fmt.Fprintf(&b, "Round: %d/%d\n", info.Round, info.Total)
+	}
+	fmt.Fprintf(&b, "Parent: %s\n", strings.Join(info.Parents, " + "))
+	b.WriteString("\n")
+	fmt.Fprintf(&b, "%s: %s\n", RunIDTrailer, info.RunID)
+
+	return b.String()
+}
+
+// summarizeLine turns Claude's result text into a commit subject line
+func summarizeLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#*->` "))
+		line = strings.Trim(line, "*`")
+		if line != "" {
+			return display.Truncate(line, maxSubjectLen)
+		}
+	}
+	return ""
+}
+
+// flattenLine collapses newlines and repeated whitespace into single spaces
+func flattenLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// commitCandidate squashes the current branch into a single commit whose message
+// is built from the run. When SummarizeCommits is set, Claude is asked for the
+// subject line; otherwise it comes from the step's result text.
+func (r *EvolutionRunner) commitCandidate(info commitInfo) error {
+	info.RunID = r.runID
+	info.Total = r.config.Iterations
+
+	if r.config.SummarizeCommits {
+		summary, err := r.runPrompt(summarizeCommitText, r.promptOptions("", ""), r.emitter)
+		if err != nil {
+			return err
+		}
+		info.Summary = summary.Text
+		info.CostUSD += summary.CostUSD
+	}
+
+	message := r.redact(buildCommitMessage(info))
+	if err := r.gitClient.SquashCommits(r.originalBranch, message); err != nil {
+		return err
+	}
+	r.pendingBranch = ""
+
+	diff, err := r.gitClient.Diff(r.originalBranch, info.Branch)
+	if err != nil {
+		return err
+	}
+	subject, _, _ := strings.Cut(message, "\n")
+	r.emitter.Emit(events.EventCandidateCommitted, events.CandidateCommittedData{
+		BranchName: info.Branch,
+		Round:      info.Round,
+		Parents:    info.Parents,
+		Subject:    subject,
+		Diff:       diff,
+		CostUSD:    info.CostUSD,
+	})
+
+	return r.recordCandidate(info)
+}
@@ -0,0 +1,108 @@
+package evolve
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCommitMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     commitInfo
+		expected string
+	}{
+		{
+			name: "initial implementation without summary",
+			info: commitInfo{
+				RunID:   "20250102-150405-a3f9c2",
+				Prompt:  "implement a snake game",
+				Round:   0,
+				Total:   3,
+				Parents: []string{"main"},
+			},
+			expected: "Initial implementation\n\n" +
+				"Prompt: implement a snake game\n" +
+				"Round: initial\n" +
+				"Parent: main\n\n" +
+				"Agent-Exec-Run-Id: 20250102-150405-a3f9c2\n",
+		},
+		{
+			name: "round with summary from result text",
+			info: commitInfo{
+				RunID:   "20250102-150405-a3f9c2",
+				Prompt:  "add tests\nfor the parser",
+				Round:   2,
+				Total:   3,
+				Parents: []string{"impl-abc123"},
+				Summary: "\n## Added parser tests\n\nDetails follow.",
+			},
+			expected: "Added parser tests\n\n" +
+				"Prompt: add tests for the parser\n" +
+				"Round: 2/3\n" +
+				"Parent: impl-abc123\n\n" +
+				"Agent-Exec-Run-Id: 20250102-150405-a3f9c2\n",
+		},
+		{
+			name: "crossover with two parents",
+			info: commitInfo{
+				RunID:   "run",
+				Prompt:  "combine",
+				Round:   1,
+				Total:   2,
+				Parents: []string{"impl-aaa111", "impl-bbb222"},
+			},
+			expected: "Evolve round 1/2 candidate\n\n" +
+				"Prompt: combine\n" +
+				"Round: 1/2\n" +
+				"Parent: impl-aaa111 + impl-bbb222\n\n" +
+				"Agent-Exec-Run-Id: run\n",
+		},
+		{
+			name: "round without summary",
+			info: commitInfo{
+				RunID:   "run",
+				Prompt:  "improve",
+				Round:   1,
+				Total:   5,
+				Parents: []string{"impl-abc123"},
+			},
+			expected: "Evolve round 1/5 candidate\n\n" +
+				"Prompt: improve\n" +
+				"Round: 1/5\n" +
+				"Parent: impl-abc123\n\n" +
+				"Agent-Exec-Run-Id: run\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildCommitMessage(tt.info)
+			if got != tt.expected {
+				t.Errorf("buildCommitMessage() = %q; want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSummarizeLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "empty", input: "", expected: ""},
+		{name: "plain line", input: "Fixed the bug", expected: "Fixed the bug"},
+		{name: "markdown heading", input: "# Summary\nmore", expected: "Summary"},
+		{name: "bold bullet", input: "- **Refactored parser**", expected: "Refactored parser"},
+		{name: "long line is truncated", input: strings.Repeat("a", 100), expected: strings.Repeat("a", 69) + "..."},
+		{name: "long cjk line is cut between characters", input: strings.Repeat("难", 100), expected: strings.Repeat("难", 69) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeLine(tt.input); got != tt.expected {
+				t.Errorf("summarizeLine(%q) = %q; want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
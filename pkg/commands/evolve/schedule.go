@@ -0,0 +1,68 @@
+package evolve
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+)
+
+// Prompt schedules for choosing the improvement prompt of each round
+const (
+	ScheduleCycle  = "cycle"
+	ScheduleRandom = "random"
+)
+
+// LoadPromptSchedule reads improvement prompts from a file, one prompt per line.
+// Blank lines and lines starting with "#" are ignored.
+func LoadPromptSchedule(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt file: %w", err)
+	}
+
+	var prompts []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prompts = append(prompts, line)
+	}
+
+	if len(prompts) == 0 {
+		return nil, fmt.Errorf("prompt file contains no prompts: %s", path)
+	}
+
+	return prompts, nil
+}
+
+// validateSchedule checks the improvement prompts and schedule name
+func validateSchedule(prompts []string, schedule string) error {
+	if len(prompts) == 0 {
+		return errors.New("at least one improvement prompt is required")
+	}
+
+	for _, prompt := range prompts {
+		if strings.TrimSpace(prompt) == "" {
+			return errors.New("improvement prompts cannot be empty")
+		}
+	}
+
+	switch schedule {
+	case "", ScheduleCycle, ScheduleRandom:
+		return nil
+	default:
+		return fmt.Errorf("unknown prompt schedule: %s (expected %s or %s)", schedule, ScheduleCycle, ScheduleRandom)
+	}
+}
+
+// improvePromptFor picks the improvement prompt for the given 1-based round
+func (r *EvolutionRunner) improvePromptFor(round int) string {
+	prompts := r.config.ImprovePrompts
+	if r.config.PromptSchedule == ScheduleRandom {
+		return prompts[rand.IntN(len(prompts))]
+	}
+	return prompts[(round-1)%len(prompts)]
+}
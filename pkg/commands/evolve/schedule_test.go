@@ -0,0 +1,112 @@
+package evolve
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadPromptSchedule(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		want        []string
+		expectedErr string
+	}{
+		{
+			name:    "one prompt per line",
+			content: "add tests\noptimize performance\npolish UX\n",
+			want:    []string{"add tests", "optimize performance", "polish UX"},
+		},
+		{
+			name:    "blank lines and comments are skipped",
+			content: "# focus areas\n\n  add tests  \n\n# later\npolish UX",
+			want:    []string{"add tests", "polish UX"},
+		},
+		{
+			name:        "only comments",
+			content:     "# nothing here\n\n",
+			expectedErr: "contains no prompts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "prompts.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatalf("failed to write prompt file: %v", err)
+			}
+
+			got, err := LoadPromptSchedule(path)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("LoadPromptSchedule() error = %v; want to contain %q", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadPromptSchedule() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadPromptSchedule() = %v; want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		prompts  []string
+		schedule string
+		wantErr  bool
+	}{
+		{name: "default schedule", prompts: []string{"improve"}, schedule: "", wantErr: false},
+		{name: "cycle", prompts: []string{"a", "b"}, schedule: ScheduleCycle, wantErr: false},
+		{name: "random", prompts: []string{"a", "b"}, schedule: ScheduleRandom, wantErr: false},
+		{name: "unknown schedule", prompts: []string{"a"}, schedule: "shuffle", wantErr: true},
+		{name: "no prompts", prompts: nil, schedule: ScheduleCycle, wantErr: true},
+		{name: "blank prompt", prompts: []string{"a", "  "}, schedule: ScheduleCycle, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchedule(tt.prompts, tt.schedule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSchedule(%v, %q) error = %v; wantErr %v", tt.prompts, tt.schedule, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImprovePromptFor(t *testing.T) {
+	prompts := []string{"add tests", "optimize performance", "polish UX"}
+
+	t.Run("cycle", func(t *testing.T) {
+		r := &EvolutionRunner{config: EvolveConfig{ImprovePrompts: prompts, PromptSchedule: ScheduleCycle}}
+		want := []string{"add tests", "optimize performance", "polish UX", "add tests"}
+		for i, expected := range want {
+			if got := r.improvePromptFor(i + 1); got != expected {
+				t.Errorf("improvePromptFor(%d) = %q; want %q", i+1, got, expected)
+			}
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		r := &EvolutionRunner{config: EvolveConfig{ImprovePrompts: prompts, PromptSchedule: ScheduleRandom}}
+		for round := 1; round <= 20; round++ {
+			got := r.improvePromptFor(round)
+			found := false
+			for _, p := range prompts {
+				if got == p {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("improvePromptFor(%d) = %q; not in schedule", round, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package lineage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/LinHanLab/agent-exec/pkg/display"
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+const maxPromptDisplayLen = 50
+
+// SelectRun returns the records of the given run, or of the most recent run when runID is empty
+func SelectRun(records []git.LineageRecord, runID string) (string, []git.LineageRecord, error) {
+	if len(records) == 0 {
+		return "", nil, fmt.Errorf("no lineage recorded in this repository")
+	}
+
+	if runID == "" {
+		for _, record := range records {
+			// Run IDs start with a timestamp, so the greatest one is the latest run
+			if record.RunID > runID {
+				runID = record.RunID
+			}
+		}
+	}
+
+	var selected []git.LineageRecord
+	for _, record := range records {
+		if record.RunID == runID {
+			selected = append(selected, record)
+		}
+	}
+
+	if len(selected) == 0 {
+		return "", nil, fmt.Errorf("no lineage recorded for run %s", runID)
+	}
+
+	return runID, selected, nil
+}
+
+// Render prints the family tree of a run's candidates
+func Render(w io.Writer, runID string, records []git.LineageRecord) error {
+	byBranch := make(map[string]git.LineageRecord, len(records))
+	for _, record := range records {
+		byBranch[record.Branch] = record
+	}
+
+	// Candidates hang under their first parent; crossover parents are annotated
+	children := make(map[string][]git.LineageRecord)
+	var roots []string
+	for _, record := range records {
+		parent := ""
+		if len(record.Parents) > 0 {
+			parent = record.Parents[0]
+		}
+		if _, isCandidate := byBranch[parent]; !isCandidate && !contains(roots, parent) {
+			roots = append(roots, parent)
+		}
+		children[parent] = append(children[parent], record)
+	}
+	for parent := range children {
+		sort.Slice(children[parent], func(i, j int) bool {
+			return children[parent][i].CreatedAt.Before(children[parent][j].CreatedAt)
+		})
+	}
+	sort.Strings(roots)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "🧬 Run %s\n", runID)
+	for _, root := range roots {
+		if root == "" {
+			b.WriteString("(unknown base)\n")
+		} else {
+			b.WriteString(root + "\n")
+		}
+		renderChildren(&b, children, root, "")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderChildren writes the subtree below parent using box-drawing connectors
+func renderChildren(b *strings.Builder, children map[string][]git.LineageRecord, parent, prefix string) {
+	kids := children[parent]
+	for i, record := range kids {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(kids)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+		b.WriteString(prefix + connector + describe(record) + "\n")
+		renderChildren(b, children, record.Branch, prefix+childPrefix)
+	}
+}
+
+// describe summarizes a single candidate on one line
+func describe(record git.LineageRecord) string {
+	parts := []string{record.Branch}
+
+	if record.Round == 0 {
+		parts = append(parts, "initial")
+	} else {
+		parts = append(parts, fmt.Sprintf("round %d", record.Round))
+	}
+
+	verdict := record.Verdict
+	if record.Fitness > 0 {
+		verdict += fmt.Sprintf(" ×%d", record.Fitness)
+	}
+	parts = append(parts, verdict)
+
+	if record.CostUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.4f", record.CostUSD))
+	}
+	if len(record.Parents) > 1 {
+		parts = append(parts, "crossover with "+strings.Join(record.Parents[1:], ", "))
+	}
+
+	prompt := display.Truncate(strings.Join(strings.Fields(record.Prompt), " "), maxPromptDisplayLen)
+	if prompt != "" {
+		parts = append(parts, fmt.Sprintf("%q", prompt))
+	}
+
+	if record.Final {
+		parts = append(parts, "🏆 final")
+	}
+
+	return strings.Join(parts, "  ")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package lineage
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/git"
+)
+
+func testRecords() []git.LineageRecord {
+	base := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	return []git.LineageRecord{
+		{RunID: "20250102-150000-aaaaaa", Branch: "impl-a", Parents: []string{"main"}, Round: 0, Prompt: "implement a snake game", Verdict: git.VerdictLost, Fitness: 1, CreatedAt: base},
+		{RunID: "20250102-150000-aaaaaa", Branch: "impl-b", Parents: []string{"impl-a"}, Round: 1, Prompt: "add tests", Verdict: git.VerdictWon, Fitness: 2, CostUSD: 0.25, CreatedAt: base.Add(time.Minute)},
+		{RunID: "20250102-150000-aaaaaa", Branch: "impl-c", Parents: []string{"impl-b", "impl-a"}, Round: 1, Prompt: "combine", Verdict: git.VerdictLost, CreatedAt: base.Add(2 * time.Minute)},
+		{RunID: "20250102-150000-aaaaaa", Branch: "impl-d", Parents: []string{"impl-b"}, Round: 2, Prompt: "polish UX", Verdict: git.VerdictWon, Fitness: 1, Final: true, CreatedAt: base.Add(3 * time.Minute)},
+		{RunID: "20250101-090000-bbbbbb", Branch: "impl-old", Parents: []string{"main"}, Verdict: git.VerdictPending, CreatedAt: base.Add(-24 * time.Hour)},
+	}
+}
+
+func TestSelectRun(t *testing.T) {
+	records := testRecords()
+
+	runID, selected, err := SelectRun(records, "")
+	if err != nil {
+		t.Fatalf("SelectRun() unexpected error: %v", err)
+	}
+	if runID != "20250102-150000-aaaaaa" {
+		t.Errorf("SelectRun() picked run %q; want latest run", runID)
+	}
+	if len(selected) != 4 {
+		t.Errorf("SelectRun() returned %d records; want 4", len(selected))
+	}
+
+	runID, selected, err = SelectRun(records, "20250101-090000-bbbbbb")
+	if err != nil {
+		t.Fatalf("SelectRun() unexpected error: %v", err)
+	}
+	if runID != "20250101-090000-bbbbbb" || len(selected) != 1 {
+		t.Errorf("SelectRun() = %q with %d records; want older run with 1 record", runID, len(selected))
+	}
+
+	if _, _, err := SelectRun(records, "missing"); err == nil {
+		t.Error("SelectRun() expected error for unknown run")
+	}
+	if _, _, err := SelectRun(nil, ""); err == nil {
+		t.Error("SelectRun() expected error when no lineage exists")
+	}
+}
+
+func TestRender(t *testing.T) {
+	_, records, err := SelectRun(testRecords(), "")
+	if err != nil {
+		t.Fatalf("SelectRun() unexpected error: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Render(buf, "20250102-150000-aaaaaa", records); err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	expected := `🧬 Run 20250102-150000-aaaaaa
+main
+└── impl-a  initial  lost ×1  "implement a snake game"
+    └── impl-b  round 1  won ×2  $0.2500  "add tests"
+        ├── impl-c  round 1  lost  crossover with impl-a  "combine"
+        └── impl-d  round 2  won ×1  "polish UX"  🏆 final
+`
+	if got := buf.String(); got != expected {
+		t.Errorf("Render() output mismatch\ngot:\n%s\nwant:\n%s", got, expected)
+	}
+
+	if !strings.Contains(buf.String(), "🏆") {
+		t.Error("Expected output to mark the final winner")
+	}
+}
+
+func TestDescribe_TruncatesPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{name: "ascii", prompt: strings.Repeat("a", 60), want: strings.Repeat("a", 47) + "..."},
+		{name: "cjk", prompt: strings.Repeat("贪吃蛇", 20), want: strings.Repeat("贪吃蛇", 15) + "贪吃..."},
+		{name: "emoji", prompt: strings.Repeat("🐍", 60), want: strings.Repeat("🐍", 47) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := describe(git.LineageRecord{Branch: "impl-a", Verdict: git.VerdictWon, Prompt: tt.prompt})
+			if want := fmt.Sprintf("%q", tt.want); !strings.HasSuffix(got, want) {
+				t.Errorf("describe() = %q; want it to end with %s", got, want)
+			}
+		})
+	}
+}
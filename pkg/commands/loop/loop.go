@@ -21,11 +21,55 @@ func ValidateLoopArgs(iterations int, prompt string) error {
 	return claude.ValidatePrompt(prompt)
 }
 
-// RunPromptLoop executes a prompt in iterations with configurable sleep
-func RunPromptLoop(iterations int, sleep time.Duration, prompt string, opts *claude.PromptOptions, emitter events.Emitter) error {
+// SessionOptions controls whether iterations continue the previous iteration's Claude session
+type SessionOptions struct {
+	Continue   bool // Resume the session of the previous iteration instead of starting a new one
+	ResetEvery int  // Start a fresh session every N iterations to keep context bounded (0 = never)
+}
+
+// Validate rejects a negative reset interval and a reset interval without session continuation
+func (s SessionOptions) Validate() error {
+	if s.ResetEvery < 0 {
+		return errors.New("--reset-session-every must not be negative")
+	}
+	if s.ResetEvery > 0 && !s.Continue {
+		return errors.New("--reset-session-every requires --continue-session")
+	}
+	return nil
+}
+
+// resumeSession returns the session iteration i should resume, or "" for a new session
+func (s SessionOptions) resumeSession(i int, lastSession string) string {
+	if !s.Continue || lastSession == "" {
+		return ""
+	}
+	if s.ResetEvery > 0 && (i-1)%s.ResetEvery == 0 {
+		return ""
+	}
+	return lastSession
+}
+
+// FailedIterationsError reports a loop that ran to completion with failed iterations
+type FailedIterationsError struct {
+	Failed int
+	Total  int
+}
+
+func (e *FailedIterationsError) Error() string {
+	return fmt.Sprintf("%d of %d iterations failed", e.Failed, e.Total)
+}
+
+// RunPromptLoop executes a prompt in iterations with configurable sleep.
+// It returns a *FailedIterationsError when iterations failed, and stops early
+// with an error wrapping claude.ErrBudgetExhausted once opts.Budget is used up.
+// Aborting opts.Control stops it like an interrupt.
+func RunPromptLoop(iterations int, sleep time.Duration, prompt string, opts *claude.PromptOptions, session SessionOptions, emitter events.Emitter) error {
 	if err := ValidateLoopArgs(iterations, prompt); err != nil {
 		return err
 	}
+	if err := session.Validate(); err != nil {
+		return err
+	}
 
 	failedIterations := 0
 
@@ -43,32 +87,55 @@ func RunPromptLoop(iterations int, sleep time.Duration, prompt string, opts *cla
 	})
 
 	loopStartTime := time.Now()
+	lastSession := ""
+	completedIterations := iterations
+	var budgetErr error
 
 	// Run the iteration loop
 	for i := 1; i <= iterations; i++ {
 		// Check for interrupt before starting iteration
-		select {
-		case <-sigChan:
+		if interrupted(sigChan, opts.Control) {
 			emitter.Emit(events.EventLoopInterrupted, events.LoopInterruptedData{
 				CompletedIterations: i - 1,
 				TotalIterations:     iterations,
 			})
-			return fmt.Errorf("interrupted")
-		default:
+			return claude.ErrInterrupted
 		}
 
+		iterOpts := *opts
+		iterOpts.Resume = session.resumeSession(i, lastSession)
+
 		emitter.Emit(events.EventIterationStarted, events.IterationStartedData{
 			Current: i,
 			Total:   iterations,
+			Resume:  iterOpts.Resume,
 		})
 
 		// Execute prompt
 		startTime := time.Now()
-		if _, err := claude.RunPrompt(prompt, opts, emitter); err != nil {
+		result, err := claude.RunPrompt(prompt, &iterOpts, emitter)
+		if result.SessionID != "" {
+			lastSession = result.SessionID
+		}
+		if errors.Is(err, claude.ErrAborted) {
+			emitter.Emit(events.EventLoopInterrupted, events.LoopInterruptedData{
+				CompletedIterations: i - 1,
+				TotalIterations:     iterations,
+			})
+			return claude.ErrInterrupted
+		}
+		if errors.Is(err, claude.ErrBudgetExhausted) {
+			// Stop without counting the iteration that never ran
+			completedIterations = i - 1
+			budgetErr = err
+			break
+		}
+		if err != nil {
 			emitter.Emit(events.EventIterationFailed, events.IterationFailedData{
 				Current: i,
 				Total:   iterations,
 				Error:   err,
+				Reason:  claude.FailureReason(err),
 			})
 			failedIterations++
 		} else {
@@ -86,28 +153,53 @@ func RunPromptLoop(iterations int, sleep time.Duration, prompt string, opts *cla
 				Duration: sleep,
 			})
 
-			// Interruptible sleep
+			// Interruptible sleep, cut short by opts.Control
 			timer := time.NewTimer(sleep)
+			stop := false
 			select {
 			case <-sigChan:
-				timer.Stop()
+				stop = true
+			case <-opts.Control.Aborted():
+				stop = true
+			case <-opts.Control.Skipped():
+			case <-timer.C:
+			}
+			timer.Stop()
+			if stop {
 				emitter.Emit(events.EventLoopInterrupted, events.LoopInterruptedData{
 					CompletedIterations: i,
 					TotalIterations:     iterations,
 				})
-				return fmt.Errorf("interrupted")
-			case <-timer.C:
+				return claude.ErrInterrupted
 			}
 		}
 	}
 
 	// Print completion summary
 	emitter.Emit(events.EventLoopCompleted, events.LoopCompletedData{
-		TotalIterations:      iterations,
-		SuccessfulIterations: iterations - failedIterations,
+		TotalIterations:      completedIterations,
+		SuccessfulIterations: completedIterations - failedIterations,
 		FailedIterations:     failedIterations,
 		TotalDuration:        time.Since(loopStartTime),
 	})
 
+	if budgetErr != nil {
+		return budgetErr
+	}
+	if failedIterations > 0 {
+		return &FailedIterationsError{Failed: failedIterations, Total: iterations}
+	}
 	return nil
 }
+
+// interrupted reports whether a signal arrived or control was aborted
+func interrupted(sigChan <-chan os.Signal, control *claude.Control) bool {
+	select {
+	case <-sigChan:
+		return true
+	case <-control.Aborted():
+		return true
+	default:
+		return false
+	}
+}
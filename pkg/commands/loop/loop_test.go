@@ -1,10 +1,23 @@
 package loop
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/claude"
+	"github.com/LinHanLab/agent-exec/pkg/claude/claudetest"
+	"github.com/LinHanLab/agent-exec/pkg/events"
 )
 
+func TestMain(m *testing.M) {
+	claudetest.Main()
+	os.Exit(m.Run())
+}
+
 func TestValidateLoopArgs(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -75,3 +88,193 @@ func TestValidateLoopArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestRunPromptLoop_FakeClaude(t *testing.T) {
+	fake := claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{
+		{Call: 2, ErrorType: "error_max_turns"},
+		{Files: map[string]string{"notes.md": "refined\n"}, Text: "refined notes"},
+	}})
+	dir := t.TempDir()
+
+	emitter := events.NewChannelEmitter(1000)
+	err := RunPromptLoop(3, 0, "refine the notes", &claude.PromptOptions{Dir: dir}, SessionOptions{}, emitter)
+	emitter.Close()
+	var failedErr *FailedIterationsError
+	if !errors.As(err, &failedErr) || failedErr.Failed != 1 || failedErr.Total != 3 {
+		t.Fatalf("RunPromptLoop error = %v; want 1 of 3 iterations failed", err)
+	}
+
+	var completed *events.LoopCompletedData
+	var failed []int
+	var reason string
+	for event := range emitter.Subscribe() {
+		switch data := event.Data.(type) {
+		case events.IterationFailedData:
+			failed = append(failed, data.Current)
+			reason = data.Reason
+		case events.LoopCompletedData:
+			completed = &data
+		}
+	}
+
+	if len(failed) != 1 || failed[0] != 2 {
+		t.Errorf("got failed iterations %v; want [2]", failed)
+	}
+	if reason != string(claude.ErrorMaxTurns) {
+		t.Errorf("got failure reason %q; want %q", reason, claude.ErrorMaxTurns)
+	}
+	if completed == nil || completed.SuccessfulIterations != 2 || completed.FailedIterations != 1 {
+		t.Errorf("got completion %+v; want 2 successful and 1 failed", completed)
+	}
+	if len(fake.Calls(t)) != 3 {
+		t.Errorf("got %d claude calls; want 3", len(fake.Calls(t)))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.md")); err != nil {
+		t.Errorf("notes.md not written: %v", err)
+	}
+}
+
+func TestRunPromptLoop_Budget(t *testing.T) {
+	fake := claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{
+		{Text: "ok", CostUSD: 0.6},
+	}})
+
+	emitter := events.NewChannelEmitter(1000)
+	opts := &claude.PromptOptions{Dir: t.TempDir(), Budget: claude.NewBudget(1)}
+	err := RunPromptLoop(5, 0, "refine", opts, SessionOptions{}, emitter)
+	emitter.Close()
+	if !errors.Is(err, claude.ErrBudgetExhausted) {
+		t.Fatalf("RunPromptLoop error = %v; want budget exhausted", err)
+	}
+
+	var completed *events.LoopCompletedData
+	for event := range emitter.Subscribe() {
+		if data, ok := event.Data.(events.LoopCompletedData); ok {
+			completed = &data
+		}
+	}
+	if len(fake.Calls(t)) != 2 {
+		t.Errorf("got %d claude calls; want 2 before the $1 budget ran out", len(fake.Calls(t)))
+	}
+	if completed == nil || completed.TotalIterations != 2 || completed.SuccessfulIterations != 2 {
+		t.Errorf("got completion %+v; want 2 iterations run", completed)
+	}
+}
+
+func TestRunPromptLoop_Control(t *testing.T) {
+	fake := claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{
+		{Text: "ok"},
+	}})
+
+	// A skipped sleep lets the second iteration start right away
+	control := claude.NewControl()
+	control.SkipSleep()
+	opts := &claude.PromptOptions{Dir: t.TempDir(), Control: control}
+	if err := RunPromptLoop(2, time.Hour, "refine", opts, SessionOptions{}, events.NewNullEmitter()); err != nil {
+		t.Fatalf("RunPromptLoop failed: %v", err)
+	}
+	if len(fake.Calls(t)) != 2 {
+		t.Errorf("got %d claude calls; want 2", len(fake.Calls(t)))
+	}
+
+	control.Abort()
+	err := RunPromptLoop(2, 0, "refine", opts, SessionOptions{}, events.NewNullEmitter())
+	if !errors.Is(err, claude.ErrInterrupted) {
+		t.Errorf("RunPromptLoop error = %v; want interrupted", err)
+	}
+	if len(fake.Calls(t)) != 2 {
+		t.Errorf("got %d claude calls after abort; want none", len(fake.Calls(t))-2)
+	}
+}
+
+func TestRunPromptLoop_ContinueSession(t *testing.T) {
+	fake := claudetest.Install(t, claudetest.Script{Steps: []claudetest.Step{
+		{Text: "ok"},
+	}})
+
+	session := SessionOptions{Continue: true, ResetEvery: 2}
+	err := RunPromptLoop(4, 0, "refine", &claude.PromptOptions{Dir: t.TempDir()}, session, events.NewNullEmitter())
+	if err != nil {
+		t.Fatalf("RunPromptLoop failed: %v", err)
+	}
+
+	// The fake names each call's session fake-session-<call>
+	want := []string{"", "fake-session-1", "", "fake-session-3"}
+	calls := fake.Calls(t)
+	if len(calls) != len(want) {
+		t.Fatalf("got %d calls; want %d", len(calls), len(want))
+	}
+	for i, call := range calls {
+		if got := resumeArg(call.Args); got != want[i] {
+			t.Errorf("call %d resumed %q; want %q", i+1, got, want[i])
+		}
+	}
+}
+
+func TestSessionOptions_ResumeSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		session     SessionOptions
+		iteration   int
+		lastSession string
+		want        string
+	}{
+		{name: "disabled", session: SessionOptions{}, iteration: 2, lastSession: "s1", want: ""},
+		{name: "first iteration", session: SessionOptions{Continue: true}, iteration: 1, lastSession: "", want: ""},
+		{name: "continues", session: SessionOptions{Continue: true}, iteration: 5, lastSession: "s4", want: "s4"},
+		{name: "reset boundary", session: SessionOptions{Continue: true, ResetEvery: 3}, iteration: 4, lastSession: "s3", want: ""},
+		{name: "within reset window", session: SessionOptions{Continue: true, ResetEvery: 3}, iteration: 5, lastSession: "s4", want: "s4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.resumeSession(tt.iteration, tt.lastSession); got != tt.want {
+				t.Errorf("resumeSession(%d, %q) = %q; want %q", tt.iteration, tt.lastSession, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		session SessionOptions
+		wantErr string
+	}{
+		{name: "disabled", session: SessionOptions{}},
+		{name: "continue", session: SessionOptions{Continue: true}},
+		{name: "continue with reset", session: SessionOptions{Continue: true, ResetEvery: 3}},
+		{name: "negative reset", session: SessionOptions{Continue: true, ResetEvery: -1}, wantErr: "must not be negative"},
+		{name: "reset without continue", session: SessionOptions{ResetEvery: 3}, wantErr: "requires --continue-session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.session.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v; want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v; want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	err := RunPromptLoop(2, 0, "refine", nil, SessionOptions{ResetEvery: 2}, events.NewNullEmitter())
+	if err == nil {
+		t.Error("RunPromptLoop accepted --reset-session-every without --continue-session")
+	}
+}
+
+// resumeArg returns the value of the --resume flag, or ""
+func resumeArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--resume" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
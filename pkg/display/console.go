@@ -18,15 +18,39 @@ type ConsoleFormatter struct {
 	verbose       bool
 	textFormatter TextFormatter
 	contentFilter *ContentFilter
+	diffs         bool
 }
 
-func NewConsoleFormatter(writer io.Writer, verbose bool) *ConsoleFormatter {
-	return &ConsoleFormatter{
+// ConsoleOption configures a ConsoleFormatter
+type ConsoleOption func(*ConsoleFormatter)
+
+// WithDiffs renders Edit and MultiEdit calls as colored diffs and Write calls
+// as file summaries instead of their JSON input
+func WithDiffs() ConsoleOption {
+	return func(f *ConsoleFormatter) {
+		f.diffs = true
+	}
+}
+
+// WithContentFilter replaces the default filter deciding which tool input and
+// output is hidden, truncated or redacted
+func WithContentFilter(filter *ContentFilter) ConsoleOption {
+	return func(f *ConsoleFormatter) {
+		f.contentFilter = filter
+	}
+}
+
+func NewConsoleFormatter(writer io.Writer, verbose bool, opts ...ConsoleOption) *ConsoleFormatter {
+	f := &ConsoleFormatter{
 		writer:        writer,
 		verbose:       verbose,
 		textFormatter: NewTextFormatter(),
 		contentFilter: NewContentFilter(verbose),
 	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
 var _ Formatter = (*ConsoleFormatter)(nil)
@@ -42,6 +66,7 @@ func (f *ConsoleFormatter) Format(event events.Event) error {
 		TextFormatter: f.textFormatter,
 		ContentFilter: f.contentFilter,
 		Verbose:       f.verbose,
+		Diffs:         f.diffs,
 	}
 
 	output, err := formatter(event, ctx)
@@ -49,6 +74,16 @@ func (f *ConsoleFormatter) Format(event events.Event) error {
 		return err
 	}
 
+	// Formatters return nothing for events hidden at this verbosity
+	if output == "" {
+		return nil
+	}
+
+	// Nest subagent output under the Task tool call that started it
+	if parentToolUseID(event) != "" {
+		output = f.textFormatter.IndentContent(output)
+	}
+
 	_, err = fmt.Fprint(f.writer, "\n")
 	if err != nil {
 		return fmt.Errorf("failed to write spacing to console: %w", err)
@@ -2,6 +2,7 @@ package display
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -68,6 +69,57 @@ func TestConsoleFormatter_RunPromptStarted(t *testing.T) {
 	}
 }
 
+func TestConsoleFormatter_SessionStarted(t *testing.T) {
+	tools := make([]string, 20)
+	for i := range tools {
+		tools[i] = fmt.Sprintf("Tool%d", i)
+	}
+
+	tests := []struct {
+		name        string
+		verbose     bool
+		wantLast    bool
+		wantEllipse bool
+	}{
+		{name: "tool list truncated", verbose: false, wantLast: false, wantEllipse: true},
+		{name: "verbose lists all tools", verbose: true, wantLast: true, wantEllipse: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			formatter := NewConsoleFormatter(buf, tt.verbose)
+
+			err := formatter.Format(events.Event{
+				Type: events.EventClaudeSessionStarted,
+				Data: events.SessionStartedData{
+					SessionID:      "abc-123",
+					Model:          "claude-sonnet-4-5",
+					Tools:          tools,
+					PermissionMode: "acceptEdits",
+				},
+			})
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			stripped := stripANSI(buf.String())
+			if !strings.Contains(stripped, "🪪") || !strings.Contains(stripped, "claude-sonnet-4-5") {
+				t.Errorf("Expected session title with model, got %q", stripped)
+			}
+			if !strings.Contains(stripped, "Tools (20)") {
+				t.Errorf("Expected tool count, got %q", stripped)
+			}
+			if got := strings.Contains(stripped, "Tool19"); got != tt.wantLast {
+				t.Errorf("Tool19 listed = %v; want %v", got, tt.wantLast)
+			}
+			if got := strings.Contains(stripped, "(8 more)"); got != tt.wantEllipse {
+				t.Errorf("truncation note shown = %v; want %v", got, tt.wantEllipse)
+			}
+		})
+	}
+}
+
 func TestConsoleFormatter_ToolUse(t *testing.T) {
 	buf := &bytes.Buffer{}
 	formatter := NewConsoleFormatter(buf, false)
@@ -128,6 +180,81 @@ func TestConsoleFormatter_ToolUse(t *testing.T) {
 	}
 }
 
+func TestConsoleFormatter_Thinking(t *testing.T) {
+	tests := []struct {
+		name     string
+		verbose  bool
+		parent   string
+		wantText bool
+	}{
+		{name: "hidden by default", verbose: false, wantText: false},
+		{name: "shown when verbose", verbose: true, wantText: true},
+		{name: "subagent thinking is nested", verbose: true, parent: "toolu_1", wantText: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			formatter := NewConsoleFormatter(buf, tt.verbose)
+
+			err := formatter.Format(events.Event{
+				Type: events.EventClaudeThinking,
+				Data: events.ThinkingData{Text: "weighing options", ParentToolUseID: tt.parent},
+			})
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			stripped := stripANSI(buf.String())
+			if got := strings.Contains(stripped, "weighing options"); got != tt.wantText {
+				t.Errorf("thinking shown = %v; want %v (output %q)", got, tt.wantText, stripped)
+			}
+			if !tt.wantText && buf.Len() != 0 {
+				t.Errorf("Expected no output for hidden thinking, got %q", buf.String())
+			}
+			if tt.parent != "" && !strings.HasPrefix(buf.String(), "\n    ") {
+				t.Errorf("Expected subagent output to be indented, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestConsoleFormatter_ToolUseDiff(t *testing.T) {
+	input := map[string]interface{}{"file_path": "main.go", "old_string": "old line", "new_string": "new line"}
+
+	tests := []struct {
+		name     string
+		opts     []ConsoleOption
+		wantDiff bool
+	}{
+		{name: "hidden without diffs", wantDiff: false},
+		{name: "rendered with diffs", opts: []ConsoleOption{WithDiffs()}, wantDiff: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			formatter := NewConsoleFormatter(buf, false, tt.opts...)
+
+			err := formatter.Format(events.Event{
+				Type: events.EventClaudeToolUse,
+				Data: events.ToolUseData{Name: "Edit", Input: input},
+			})
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			stripped := stripANSI(buf.String())
+			if got := strings.Contains(stripped, "    -old line") && strings.Contains(stripped, "    +new line"); got != tt.wantDiff {
+				t.Errorf("diff shown = %v; want %v (output %q)", got, tt.wantDiff, stripped)
+			}
+			if got := strings.Contains(stripped, "hidden, use --verbose"); got == tt.wantDiff {
+				t.Errorf("JSON placeholder shown = %v; want %v", got, !tt.wantDiff)
+			}
+		})
+	}
+}
+
 func TestConsoleFormatter_EvolveStarted(t *testing.T) {
 	buf := &bytes.Buffer{}
 	formatter := NewConsoleFormatter(buf, false)
@@ -214,6 +341,89 @@ func TestConsoleFormatter_ExecutionResult(t *testing.T) {
 	}
 }
 
+func TestConsoleFormatter_ExecutionResultToolSummary(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewConsoleFormatter(buf, false)
+
+	err := formatter.Format(events.Event{
+		Type: events.EventClaudeExecutionResult,
+		Data: events.ExecutionResultData{
+			Duration: 10 * time.Second,
+			Tools: []events.ToolTiming{
+				{Name: "Read", Calls: 3, Duration: 1200 * time.Millisecond},
+				{Name: "Bash", Calls: 2, Errors: 1, Duration: 4 * time.Second},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	stripped := stripANSI(buf.String())
+	for _, want := range []string{"    🔧 Read: 3 call(s), 1.2s", "    🔧 Bash: 2 call(s), 4.0s, 1 failed"} {
+		if !strings.Contains(stripped, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, stripped)
+		}
+	}
+}
+
+func TestConsoleFormatter_ToolResultError(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewConsoleFormatter(buf, false)
+
+	err := formatter.Format(events.Event{
+		Type: events.EventClaudeToolResult,
+		Data: events.ToolResultData{
+			ToolUseID: "toolu_1",
+			Name:      "Bash",
+			Duration:  1500 * time.Millisecond,
+			IsError:   true,
+			Content:   "command not found",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	output := buf.String()
+	stripped := stripANSI(output)
+	if !strings.Contains(stripped, "❌") || !strings.Contains(stripped, "Tool Error: Bash (1.5s)") {
+		t.Errorf("Expected error title with tool name and latency, got %q", stripped)
+	}
+	if !strings.Contains(output, BoldRed) {
+		t.Error("Expected output to contain BoldRed color code")
+	}
+}
+
+func TestConsoleFormatter_RunStats(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewConsoleFormatter(buf, false)
+
+	err := formatter.Format(events.Event{
+		Type: events.EventRunStats,
+		Data: events.RunStatsData{
+			Iterations: []events.ToolStats{
+				{Label: "Iteration 1", Tools: []events.ToolCount{{Name: "Read", Calls: 2}, {Name: "Edit", Calls: 1}}, FilesTouched: []string{"a.go"}},
+			},
+			Total: events.ToolStats{
+				Label:        "Total",
+				Tools:        []events.ToolCount{{Name: "Read", Calls: 2}, {Name: "Edit", Calls: 1}},
+				FilesTouched: []string{"a.go"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	stripped := stripANSI(buf.String())
+	for _, want := range []string{"📊 Tool Usage", "Iteration 1  3      0       1      Read 2, Edit 1", "Total", "📝 Files touched:", "    a.go"} {
+		if !strings.Contains(stripped, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, stripped)
+		}
+	}
+}
+
 func TestDisplay_StartAndWait(t *testing.T) {
 	buf := &bytes.Buffer{}
 	formatter := NewConsoleFormatter(buf, false)
@@ -1,43 +1,92 @@
 package display
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 )
 
 const (
 	MaxCodeBlockLines = 10
 	MaxCodeBlockChars = 5000
+
+	// RedactedText replaces secrets masked by a Redactor
+	RedactedText = "<redacted>"
 )
 
+// FilterConfig controls how much of Claude's tool calls the console shows.
+// It can be loaded from a JSON file with LoadFilterConfig.
+type FilterConfig struct {
+	HideFields     map[string][]string `json:"hide_fields,omitempty"`      // Tool input fields hidden unless verbose, by tool
+	MaxLines       int                 `json:"max_lines,omitempty"`        // Lines shown per code block unless verbose
+	MaxChars       int                 `json:"max_chars,omitempty"`        // Characters shown per code block unless verbose
+	ToolLineLimits map[string]int      `json:"tool_line_limits,omitempty"` // MaxLines override by tool
+	AlwaysShow     []string            `json:"always_show,omitempty"`      // Tools never hidden or truncated
+	Redact         []string            `json:"redact,omitempty"`           // Extra regexes masked in all output, even in verbose mode (see NewRedactor)
+}
+
+// DefaultFilterConfig returns the built-in filter settings
+func DefaultFilterConfig() FilterConfig {
+	return FilterConfig{
+		HideFields: map[string][]string{
+			"Write": {"content"},
+			"Edit":  {"new_string", "old_string"},
+		},
+		MaxLines: MaxCodeBlockLines,
+		MaxChars: MaxCodeBlockChars,
+	}
+}
+
+// LoadFilterConfig reads a JSON filter config. Settings missing from the file
+// keep their defaults.
+func LoadFilterConfig(path string) (FilterConfig, error) {
+	cfg := DefaultFilterConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read display config: %w", err)
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid display config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
 type ContentFilter struct {
 	verbose bool
+	config  FilterConfig
 }
 
 func NewContentFilter(verbose bool) *ContentFilter {
 	return &ContentFilter{
 		verbose: verbose,
+		config:  DefaultFilterConfig(),
 	}
 }
 
-type ToolInputFilter struct {
-	ToolName string
-	Fields   []string
+// NewContentFilterWithConfig creates a content filter with custom settings
+func NewContentFilterWithConfig(verbose bool, config FilterConfig) *ContentFilter {
+	return &ContentFilter{
+		verbose: verbose,
+		config:  config,
+	}
 }
 
-var defaultToolInputFilters = []ToolInputFilter{
-	{
-		ToolName: "Write",
-		Fields:   []string{"content"},
-	},
-	{
-		ToolName: "Edit",
-		Fields:   []string{"new_string", "old_string"},
-	},
+// showAll reports whether a tool's input and output are shown in full
+func (cf *ContentFilter) showAll(toolName string) bool {
+	if cf.verbose {
+		return true
+	}
+	for _, name := range cf.config.AlwaysShow {
+		if name == toolName {
+			return true
+		}
+	}
+	return false
 }
 
 func (cf *ContentFilter) ApplyToolInputFilters(toolName string, input map[string]interface{}) map[string]interface{} {
-	if cf.verbose {
+	if cf.showAll(toolName) {
 		return input
 	}
 
@@ -46,13 +95,9 @@ func (cf *ContentFilter) ApplyToolInputFilters(toolName string, input map[string
 		filtered[k] = v
 	}
 
-	for _, filter := range defaultToolInputFilters {
-		if filter.ToolName == toolName {
-			for _, field := range filter.Fields {
-				if _, exists := filtered[field]; exists {
-					filtered[field] = "<hidden, use --verbose to see>"
-				}
-			}
+	for _, field := range cf.config.HideFields[toolName] {
+		if _, exists := filtered[field]; exists {
+			filtered[field] = "<hidden, use --verbose to see>"
 		}
 	}
 
@@ -60,23 +105,34 @@ func (cf *ContentFilter) ApplyToolInputFilters(toolName string, input map[string
 }
 
 func (cf *ContentFilter) LimitCodeBlock(content string) string {
-	if cf.verbose {
+	return cf.LimitToolCodeBlock("", content)
+}
+
+// LimitToolCodeBlock truncates a tool's input or output to the limits configured for the tool
+func (cf *ContentFilter) LimitToolCodeBlock(toolName string, content string) string {
+	if cf.showAll(toolName) {
 		return content
 	}
 
+	maxLines := cf.config.MaxLines
+	if limit, ok := cf.config.ToolLineLimits[toolName]; ok && toolName != "" {
+		maxLines = limit
+	}
+	maxChars := cf.config.MaxChars
+
 	lines := strings.Split(content, "\n")
 
-	if len(lines) > MaxCodeBlockLines {
-		hiddenLines := len(lines) - MaxCodeBlockLines
-		lines = lines[:MaxCodeBlockLines]
+	if maxLines > 0 && len(lines) > maxLines {
+		hiddenLines := len(lines) - maxLines
+		lines = lines[:maxLines]
 		lines = append(lines, fmt.Sprintf("... (%d more lines hidden, use --verbose to see all)", hiddenLines))
 	}
 
 	result := strings.Join(lines, "\n")
 
-	if len(result) > MaxCodeBlockChars {
-		hiddenChars := len(content) - MaxCodeBlockChars
-		result = result[:MaxCodeBlockChars] + fmt.Sprintf("\n... (%d more characters hidden, use --verbose to see all)", hiddenChars)
+	if maxChars > 0 && len(result) > maxChars {
+		hiddenChars := len(content) - maxChars
+		result = result[:maxChars] + fmt.Sprintf("\n... (%d more characters hidden, use --verbose to see all)", hiddenChars)
 	}
 
 	return result
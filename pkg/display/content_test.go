@@ -1,6 +1,8 @@
 package display
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -145,3 +147,57 @@ func TestContentFilter_LimitCodeBlock(t *testing.T) {
 		})
 	}
 }
+
+func TestContentFilter_Config(t *testing.T) {
+	cfg := DefaultFilterConfig()
+	cfg.HideFields["Bash"] = []string{"command"}
+	cfg.ToolLineLimits = map[string]int{"Read": 2}
+	cfg.AlwaysShow = []string{"Write"}
+
+	cf := NewContentFilterWithConfig(false, cfg)
+
+	bash := cf.ApplyToolInputFilters("Bash", map[string]interface{}{"command": "ls"})
+	if !strings.Contains(bash["command"].(string), "hidden") {
+		t.Errorf("Bash command = %v; want it hidden", bash["command"])
+	}
+
+	write := cf.ApplyToolInputFilters("Write", map[string]interface{}{"content": "body"})
+	if write["content"] != "body" {
+		t.Errorf("Write content = %v; want it shown for an always-show tool", write["content"])
+	}
+
+	content := strings.Repeat("line\n", 5)
+	if got := strings.Count(cf.LimitToolCodeBlock("Read", content), "\n"); got != 2 {
+		t.Errorf("Read output has %d newlines; want 2 (2 lines plus the hidden note)", got)
+	}
+	if got := cf.LimitToolCodeBlock("Write", strings.Repeat("line\n", 50)); strings.Contains(got, "hidden") {
+		t.Error("Expected always-show tool output not to be truncated")
+	}
+	if got := cf.LimitToolCodeBlock("Grep", content); got != content {
+		t.Errorf("Grep output = %q; want it unchanged under the default limit", got)
+	}
+}
+
+func TestLoadFilterConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "display.json")
+	if err := os.WriteFile(path, []byte(`{"max_lines": 3, "always_show": ["Bash"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFilterConfig(path)
+	if err != nil {
+		t.Fatalf("LoadFilterConfig() error: %v", err)
+	}
+	if cfg.MaxLines != 3 {
+		t.Errorf("MaxLines = %d; want 3", cfg.MaxLines)
+	}
+	if cfg.MaxChars != MaxCodeBlockChars {
+		t.Errorf("MaxChars = %d; want default %d", cfg.MaxChars, MaxCodeBlockChars)
+	}
+	if len(cfg.AlwaysShow) != 1 || cfg.AlwaysShow[0] != "Bash" {
+		t.Errorf("AlwaysShow = %v; want [Bash]", cfg.AlwaysShow)
+	}
+	if len(cfg.HideFields["Edit"]) != 2 {
+		t.Errorf("HideFields[Edit] = %v; want the defaults kept", cfg.HideFields["Edit"])
+	}
+}
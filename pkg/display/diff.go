@@ -0,0 +1,178 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxDiffCells bounds the line comparison; larger edits are shown as a
+	// full removal followed by a full addition
+	MaxDiffCells = 250000
+
+	// WriteHeadLines is the number of lines shown from a written file
+	WriteHeadLines = 5
+)
+
+// diffLine is one line of a line-based diff
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// diffLines compares two texts line by line using their longest common subsequence
+func diffLines(oldText, newText string) []diffLine {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	if len(a)*len(b) > MaxDiffCells {
+		var lines []diffLine
+		for _, line := range a {
+			lines = append(lines, diffLine{'-', line})
+		}
+		for _, line := range b {
+			lines = append(lines, diffLine{'+', line})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+	return lines
+}
+
+// splitLines splits text into lines, treating empty text as no lines
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// renderToolDiff renders Edit and MultiEdit calls as a unified diff and Write
+// calls as a file summary. It returns false for other tools or unexpected input.
+func renderToolDiff(toolName string, input map[string]interface{}, filter *ContentFilter) (string, bool) {
+	path, _ := input["file_path"].(string)
+
+	switch toolName {
+	case "Edit":
+		oldString, ok1 := input["old_string"].(string)
+		newString, ok2 := input["new_string"].(string)
+		if !ok1 || !ok2 {
+			return "", false
+		}
+		return renderDiff(toolName, path, [][2]string{{oldString, newString}}, filter), true
+
+	case "MultiEdit":
+		rawEdits, ok := input["edits"].([]interface{})
+		if !ok {
+			return "", false
+		}
+		var edits [][2]string
+		for _, raw := range rawEdits {
+			edit, ok := raw.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+			oldString, _ := edit["old_string"].(string)
+			newString, _ := edit["new_string"].(string)
+			edits = append(edits, [2]string{oldString, newString})
+		}
+		return renderDiff(toolName, path, edits, filter), true
+
+	case "Write":
+		content, ok := input["content"].(string)
+		if !ok {
+			return "", false
+		}
+		return renderWrite(path, content), true
+	}
+
+	return "", false
+}
+
+// renderDiff renders the edits to path as a colored unified diff, one hunk per edit
+func renderDiff(toolName, path string, edits [][2]string, filter *ContentFilter) string {
+	lines := []string{"--- a/" + path, "+++ b/" + path}
+	for _, edit := range edits {
+		lines = append(lines, "@@")
+		for _, line := range diffLines(edit[0], edit[1]) {
+			lines = append(lines, string(line.op)+line.text)
+		}
+	}
+
+	limited := strings.Split(filter.LimitToolCodeBlock(toolName, strings.Join(lines, "\n")), "\n")
+	for i, line := range limited {
+		limited[i] = colorDiffLine(line)
+	}
+	return strings.Join(limited, "\n")
+}
+
+// colorDiffLine colors a diff line by its prefix
+func colorDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+		return Bold + line + Reset
+	case strings.HasPrefix(line, "@@"):
+		return Cyan + line + Reset
+	case strings.HasPrefix(line, "-"):
+		return Red + line + Reset
+	case strings.HasPrefix(line, "+"):
+		return Green + line + Reset
+	default:
+		return line
+	}
+}
+
+// renderWrite summarizes a file write with its path, line count and first lines
+func renderWrite(path, content string) string {
+	lines := splitLines(content)
+	summary := fmt.Sprintf("%s%s%s (%d lines)", Bold, path, Reset, len(lines))
+
+	head := lines
+	if len(head) > WriteHeadLines {
+		head = head[:WriteHeadLines]
+	}
+	for _, line := range head {
+		summary += "\n" + Green + "+" + line + Reset
+	}
+	if hidden := len(lines) - len(head); hidden > 0 {
+		summary += fmt.Sprintf("\n... (%d more lines)", hidden)
+	}
+	return summary
+}
@@ -0,0 +1,119 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    []string
+	}{
+		{
+			name:    "changed middle line",
+			oldText: "a\nb\nc",
+			newText: "a\nB\nc",
+			want:    []string{" a", "-b", "+B", " c"},
+		},
+		{
+			name:    "insertion",
+			oldText: "a\nc\n",
+			newText: "a\nb\nc\n",
+			want:    []string{" a", "+b", " c"},
+		},
+		{
+			name:    "new text only",
+			oldText: "",
+			newText: "x",
+			want:    []string{"+x"},
+		},
+		{
+			name:    "removal only",
+			oldText: "x\ny",
+			newText: "",
+			want:    []string{"-x", "-y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, line := range diffLines(tt.oldText, tt.newText) {
+				got = append(got, string(line.op)+line.text)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("diffLines() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderToolDiff(t *testing.T) {
+	filter := NewContentFilter(false)
+
+	tests := []struct {
+		name    string
+		tool    string
+		input   map[string]interface{}
+		wantOK  bool
+		wantAll []string
+	}{
+		{
+			name:    "edit",
+			tool:    "Edit",
+			input:   map[string]interface{}{"file_path": "main.go", "old_string": "x := 1", "new_string": "x := 2"},
+			wantOK:  true,
+			wantAll: []string{"--- a/main.go", "+++ b/main.go", "-x := 1", "+x := 2"},
+		},
+		{
+			name: "multi edit has a hunk per edit",
+			tool: "MultiEdit",
+			input: map[string]interface{}{"file_path": "main.go", "edits": []interface{}{
+				map[string]interface{}{"old_string": "one", "new_string": "uno"},
+				map[string]interface{}{"old_string": "two", "new_string": "dos"},
+			}},
+			wantOK:  true,
+			wantAll: []string{"-one", "+uno", "-two", "+dos"},
+		},
+		{
+			name:    "write summary",
+			tool:    "Write",
+			input:   map[string]interface{}{"file_path": "notes.txt", "content": "1\n2\n3\n4\n5\n6\n7\n"},
+			wantOK:  true,
+			wantAll: []string{"notes.txt (7 lines)", "+1", "+5", "... (2 more lines)"},
+		},
+		{
+			name:   "other tools are not rendered",
+			tool:   "Bash",
+			input:  map[string]interface{}{"command": "ls"},
+			wantOK: false,
+		},
+		{
+			name:   "edit missing strings",
+			tool:   "Edit",
+			input:  map[string]interface{}{"file_path": "main.go"},
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := renderToolDiff(tt.tool, tt.input, filter)
+			if ok != tt.wantOK {
+				t.Fatalf("renderToolDiff() ok = %v; want %v", ok, tt.wantOK)
+			}
+			stripped := stripANSI(got)
+			for _, want := range tt.wantAll {
+				if !strings.Contains(stripped, want) {
+					t.Errorf("renderToolDiff() = %q; want it to contain %q", stripped, want)
+				}
+			}
+			if strings.Contains(stripped, "+6") {
+				t.Errorf("renderToolDiff() = %q; want the write head limited to %d lines", stripped, WriteHeadLines)
+			}
+		})
+	}
+}
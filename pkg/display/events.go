@@ -3,6 +3,8 @@ package display
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/LinHanLab/agent-exec/pkg/events"
 )
@@ -49,6 +51,49 @@ func formatRunPromptStarted(event events.Event, ctx *FormatContext) (string, err
 	return output, nil
 }
 
+// maxToolsShown limits the tool names listed when a session starts, unless verbose
+const maxToolsShown = 12
+
+func formatClaudeSessionStarted(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.SessionStartedData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	title := fmt.Sprintf("🪪 %sSession Started", timeStr)
+	if data.Model != "" {
+		title += ": " + data.Model
+	}
+
+	var lines []string
+	if data.SessionID != "" {
+		lines = append(lines, fmt.Sprintf("🆔 Session: %s", data.SessionID))
+	}
+	if data.PermissionMode != "" {
+		lines = append(lines, fmt.Sprintf("🔐 Permission Mode: %s", data.PermissionMode))
+	}
+	if len(data.Tools) > 0 {
+		tools := data.Tools
+		more := ""
+		if !ctx.Verbose && len(tools) > maxToolsShown {
+			more = fmt.Sprintf(", ... (%d more)", len(tools)-maxToolsShown)
+			tools = tools[:maxToolsShown]
+		}
+		lines = append(lines, fmt.Sprintf("🧰 Tools (%d): %s%s", len(data.Tools), strings.Join(tools, ", "), more))
+	}
+	if len(data.MCPServers) > 0 {
+		servers := make([]string, 0, len(data.MCPServers))
+		for _, server := range data.MCPServers {
+			servers = append(servers, fmt.Sprintf("%s (%s)", server.Name, server.Status))
+		}
+		lines = append(lines, fmt.Sprintf("🔌 MCP Servers: %s", strings.Join(servers, ", ")))
+	}
+
+	output := fmt.Sprintf("%s%s%s", color, title, Reset)
+	if len(lines) > 0 {
+		output += "\n" + ctx.TextFormatter.IndentContent(strings.Join(lines, "\n"))
+	}
+	return output, nil
+}
+
 func formatClaudeAssistantMessage(event events.Event, ctx *FormatContext) (string, error) {
 	data := mustGetEventData[events.AssistantMessageData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
@@ -61,11 +106,35 @@ func formatClaudeAssistantMessage(event events.Event, ctx *FormatContext) (strin
 	return coloredTitle + content, nil
 }
 
+func formatClaudeThinking(event events.Event, ctx *FormatContext) (string, error) {
+	// Thinking is only shown in verbose mode
+	if !ctx.Verbose {
+		return "", nil
+	}
+
+	data := mustGetEventData[events.ThinkingData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	title := fmt.Sprintf("🧠 %sThinking", timeStr)
+	coloredTitle := fmt.Sprintf("%s%s%s", color, title, Reset)
+
+	content := ctx.TextFormatter.FormatContentWithFrameAndColor(data.Text, color)
+
+	return coloredTitle + content, nil
+}
+
 func formatClaudeToolUse(event events.Event, ctx *FormatContext) (string, error) {
 	data := mustGetEventData[events.ToolUseData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
 	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
 
+	if ctx.Diffs {
+		if diff, ok := renderToolDiff(data.Name, data.Input, ctx.ContentFilter); ok {
+			title := fmt.Sprintf("🔧 %sTool: %s", timeStr, data.Name)
+			return fmt.Sprintf("%s%s%s\n%s", color, title, Reset, ctx.TextFormatter.IndentContent(diff)), nil
+		}
+	}
+
 	filteredInput := ctx.ContentFilter.ApplyToolInputFilters(data.Name, data.Input)
 
 	prettyJSON, err := formatPrettyJSON(filteredInput)
@@ -73,7 +142,7 @@ func formatClaudeToolUse(event events.Event, ctx *FormatContext) (string, error)
 		return "", fmt.Errorf("failed to format tool input: %w", err)
 	}
 
-	limitedJSON := ctx.ContentFilter.LimitCodeBlock(prettyJSON)
+	limitedJSON := ctx.ContentFilter.LimitToolCodeBlock(data.Name, prettyJSON)
 
 	title := fmt.Sprintf("🔧 %sTool: %s", timeStr, data.Name)
 	coloredTitle := fmt.Sprintf("%s%s%s", color, title, Reset)
@@ -87,9 +156,16 @@ func formatClaudeToolResult(event events.Event, ctx *FormatContext) (string, err
 	data := mustGetEventData[events.ToolResultData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
 	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
-	limitedContent := ctx.ContentFilter.LimitCodeBlock(data.Content)
+	limitedContent := ctx.ContentFilter.LimitToolCodeBlock(data.Name, data.Content)
 
 	title := fmt.Sprintf("📋 %sTool Result", timeStr)
+	if data.IsError {
+		color = BoldRed
+		title = fmt.Sprintf("❌ %sTool Error", timeStr)
+	}
+	if data.Name != "" {
+		title += fmt.Sprintf(": %s (%s)", data.Name, ctx.TextFormatter.FormatDuration(data.Duration))
+	}
 	coloredTitle := fmt.Sprintf("%s%s%s", color, title, Reset)
 
 	resultContent := ctx.TextFormatter.FormatContentWithFrame(limitedContent)
@@ -101,7 +177,23 @@ func formatClaudeExecutionResult(event events.Event, ctx *FormatContext) (string
 	data := mustGetEventData[events.ExecutionResultData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
 	message := fmt.Sprintf("⏱️ Execution completed in %s", ctx.TextFormatter.FormatDuration(data.Duration))
-	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
+	if data.CostUSD > 0 {
+		message += fmt.Sprintf(" (cost: $%.4f)", data.CostUSD)
+	}
+	output := fmt.Sprintf("%s%s%s", color, message, Reset)
+
+	if len(data.Tools) > 0 {
+		var lines []string
+		for _, tool := range data.Tools {
+			line := fmt.Sprintf("🔧 %s: %d call(s), %s", tool.Name, tool.Calls, ctx.TextFormatter.FormatDuration(tool.Duration))
+			if tool.Errors > 0 {
+				line += fmt.Sprintf(", %d failed", tool.Errors)
+			}
+			lines = append(lines, line)
+		}
+		output += "\n" + ctx.TextFormatter.IndentContent(strings.Join(lines, "\n"))
+	}
+	return output, nil
 }
 
 func formatLoopStarted(event events.Event, ctx *FormatContext) (string, error) {
@@ -122,6 +214,9 @@ func formatIterationStarted(event events.Event, ctx *FormatContext) (string, err
 	color := GetColorForEventType(event.Type)
 	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
 	message := fmt.Sprintf("▶️ %sIteration %d/%d started", timeStr, data.Current, data.Total)
+	if data.Resume != "" {
+		message += fmt.Sprintf(" (resuming session %s)", data.Resume)
+	}
 	return ctx.TextFormatter.ApplyReverseVideo(message, color), nil
 }
 
@@ -141,7 +236,11 @@ func formatIterationFailed(event events.Event, ctx *FormatContext) (string, erro
 	if data.Error != nil {
 		errMsg = data.Error.Error()
 	}
-	message := fmt.Sprintf("❌ %sIteration %d/%d failed: %s", timeStr, data.Current, data.Total, errMsg)
+	reason := ""
+	if data.Reason != "" {
+		reason = fmt.Sprintf(" (%s)", data.Reason)
+	}
+	message := fmt.Sprintf("❌ %sIteration %d/%d failed%s: %s", timeStr, data.Current, data.Total, reason, errMsg)
 	return ctx.TextFormatter.ApplyReverseVideo(message, color), nil
 }
 
@@ -176,6 +275,9 @@ func formatEvolveStarted(event events.Event, ctx *FormatContext) (string, error)
 	formattedTitle := ctx.TextFormatter.ApplyReverseVideo(title, color)
 
 	content := fmt.Sprintf("🔢 Iterations: %d", data.TotalIterations)
+	if data.RunID != "" {
+		content += fmt.Sprintf("\n🆔 Run: %s", data.RunID)
+	}
 	indentedContent := ctx.TextFormatter.IndentContent(content)
 
 	return formattedTitle + "\n" + indentedContent, nil
@@ -194,9 +296,33 @@ func formatImprovementStarted(event events.Event, ctx *FormatContext) (string, e
 	color := GetColorForEventType(event.Type)
 	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
 	message := fmt.Sprintf("🔨 %sImproving branch: %s", timeStr, data.BranchName)
+	output := fmt.Sprintf("%s%s%s", color, message, Reset)
+	if data.Prompt != "" {
+		output += "\n" + ctx.TextFormatter.IndentContent(fmt.Sprintf("📝 Prompt: %s", data.Prompt))
+	}
+	return output, nil
+}
+
+func formatCrossoverStarted(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.CrossoverStartedData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	message := fmt.Sprintf("🧪 %sCrossover branch: %s (from %s + %s)", timeStr, data.BranchName, data.Parent1, data.Parent2)
 	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
 }
 
+func formatChallengerFailed(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.ChallengerFailedData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	message := fmt.Sprintf("❌ %sChallenger %s failed in round %d (%s), branch %s", timeStr, data.BranchName, data.Round, data.Reason, data.Action)
+	output := fmt.Sprintf("%s%s%s", color, message, Reset)
+	if data.Error != "" {
+		output += "\n" + ctx.TextFormatter.IndentContent(data.Error)
+	}
+	return output, nil
+}
+
 func formatComparisonStarted(event events.Event, ctx *FormatContext) (string, error) {
 	data := mustGetEventData[events.ComparisonStartedData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
@@ -213,6 +339,25 @@ func formatComparisonRetry(event events.Event, ctx *FormatContext) (string, erro
 	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
 }
 
+func formatCandidateCommitted(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.CandidateCommittedData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+
+	added, removed := 0, 0
+	for _, line := range strings.Split(data.Diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+
+	message := fmt.Sprintf("📦 Committed %s: %s (+%d/-%d lines)", data.BranchName, data.Subject, added, removed)
+	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
+}
+
 func formatWinnerSelected(event events.Event, ctx *FormatContext) (string, error) {
 	data := mustGetEventData[events.WinnerSelectedData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
@@ -221,6 +366,19 @@ func formatWinnerSelected(event events.Event, ctx *FormatContext) (string, error
 	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
 }
 
+func formatEvolveFinished(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.EvolveFinishedData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	var message string
+	if data.Mode == "pr-branch" {
+		message = fmt.Sprintf("🔗 %sWinner %s ready for review as %s at %s", timeStr, data.Winner, data.Branch, shortHash(data.Commit))
+	} else {
+		message = fmt.Sprintf("🔗 %sWinner %s integrated into %s (%s) at %s", timeStr, data.Winner, data.Branch, data.Mode, shortHash(data.Commit))
+	}
+	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
+}
+
 func formatEvolveCompleted(event events.Event, ctx *FormatContext) (string, error) {
 	data := mustGetEventData[events.EvolveCompletedData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
@@ -236,6 +394,29 @@ func formatEvolveInterrupted(event events.Event, ctx *FormatContext) (string, er
 	return ctx.TextFormatter.ApplyReverseVideo(message, color), nil
 }
 
+func formatEvolveCleanup(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.EvolveCleanupData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	title := fmt.Sprintf("🧹 Cleanup after %s run", data.Outcome)
+
+	lines := []string{fmt.Sprintf("🔀 Checked out: %s", data.CheckedOut)}
+	if data.Winner != "" {
+		lines = append(lines, fmt.Sprintf("🏆 Winner: %s", data.Winner))
+	}
+	if data.IncompleteBranch != "" {
+		if data.IncompleteAction == "deleted" {
+			lines = append(lines, fmt.Sprintf("🗑️ Incomplete challenger deleted: %s", data.IncompleteBranch))
+		} else {
+			lines = append(lines, fmt.Sprintf("🏷️ Incomplete challenger kept as: %s", data.IncompleteAction))
+		}
+	}
+	if data.Error != "" {
+		lines = append(lines, fmt.Sprintf("⚠️ %s", data.Error))
+	}
+
+	return fmt.Sprintf("%s%s%s", color, title, Reset) + "\n" + ctx.TextFormatter.IndentContent(strings.Join(lines, "\n")), nil
+}
+
 func formatGitBranchCreated(event events.Event, ctx *FormatContext) (string, error) {
 	data := mustGetEventData[events.BranchCreatedData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
@@ -263,6 +444,27 @@ func formatGitBranchDeleted(event events.Event, ctx *FormatContext) (string, err
 	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
 }
 
+func formatGitBranchRenamed(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.BranchRenamedData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	message := fmt.Sprintf("🏷️ %sBranch renamed: %s → %s", timeStr, data.OldName, data.NewName)
+	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
+}
+
+func formatGitWorktreeCreated(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.WorktreeCreatedData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	message := fmt.Sprintf("🌳 %sWorktree created: %s", timeStr, data.Path)
+	if data.Branch != "" {
+		message += fmt.Sprintf(" (branch %s from %s)", data.Branch, data.Base)
+	} else {
+		message += fmt.Sprintf(" (detached at %s)", data.Base)
+	}
+	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
+}
+
 func formatGitCommitsSquashed(event events.Event, ctx *FormatContext) (string, error) {
 	data := mustGetEventData[events.CommitsSquashedData](event, string(event.Type))
 	color := GetColorForEventType(event.Type)
@@ -270,3 +472,56 @@ func formatGitCommitsSquashed(event events.Event, ctx *FormatContext) (string, e
 	message := fmt.Sprintf("📦 %sCommits squashed on branch: %s", timeStr, data.BranchName)
 	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
 }
+
+func formatGitStashed(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.StashData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	message := fmt.Sprintf("📥 %sStashed uncommitted changes: %s", timeStr, data.Message)
+	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
+}
+
+func formatGitStashRestored(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.StashData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	timeStr := fmt.Sprintf("[%s] ", ctx.TextFormatter.FormatTime())
+	message := fmt.Sprintf("📤 %sRestored stashed changes (%s)", timeStr, shortHash(data.Stash))
+	return fmt.Sprintf("%s%s%s", color, message, Reset), nil
+}
+
+// shortHash abbreviates a commit hash for display
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+func formatRunStats(event events.Event, ctx *FormatContext) (string, error) {
+	data := mustGetEventData[events.RunStatsData](event, string(event.Type))
+	color := GetColorForEventType(event.Type)
+	title := fmt.Sprintf("%s📊 Tool Usage%s", color, Reset)
+
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tCalls\tErrors\tFiles\tTools")
+	for _, row := range append(data.Iterations, data.Total) {
+		calls := 0
+		var tools []string
+		for _, tool := range row.Tools {
+			calls += tool.Calls
+			tools = append(tools, fmt.Sprintf("%s %d", tool.Name, tool.Calls))
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", row.Label, calls, row.Errors, len(row.FilesTouched), strings.Join(tools, ", "))
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to format tool usage: %w", err)
+	}
+
+	content := strings.TrimRight(table.String(), "\n")
+	if files := data.Total.FilesTouched; len(files) > 0 {
+		content += "\n\n📝 Files touched:\n" + strings.Join(files, "\n")
+	}
+
+	return title + "\n" + ctx.TextFormatter.IndentContent(content), nil
+}
@@ -16,14 +16,33 @@ type Formatter interface {
 type Display struct {
 	formatter Formatter
 	emitter   events.Emitter
+	redactor  *Redactor
 	wg        sync.WaitGroup
 }
 
-func NewDisplay(formatter Formatter, emitter events.Emitter) *Display {
-	return &Display{
+// DisplayOption configures a Display
+type DisplayOption func(*Display)
+
+// WithRedactor replaces the built-in secret redaction applied to every event
+func WithRedactor(redactor *Redactor) DisplayOption {
+	return func(d *Display) {
+		d.redactor = redactor
+	}
+}
+
+func NewDisplay(formatter Formatter, emitter events.Emitter, opts ...DisplayOption) *Display {
+	d := &Display{
 		formatter: formatter,
 		emitter:   emitter,
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.redactor == nil {
+		// Built-in patterns always compile
+		d.redactor, _ = NewRedactor(nil)
+	}
+	return d
 }
 
 func (d *Display) Start() {
@@ -32,7 +51,8 @@ func (d *Display) Start() {
 		defer d.wg.Done()
 		ch := d.emitter.Subscribe()
 		for event := range ch {
-			if err := d.formatter.Format(event); err != nil {
+			// Mask secrets before the event reaches any formatter
+			if err := d.formatter.Format(d.redactor.RedactEvent(event)); err != nil {
 				fmt.Fprintf(os.Stderr, "[display] format error: %v\n", err)
 			}
 		}
@@ -12,11 +12,14 @@ type FormatContext struct {
 	TextFormatter TextFormatter
 	ContentFilter *ContentFilter
 	Verbose       bool
+	Diffs         bool // Render file edits as diffs (see WithDiffs)
 }
 
 var eventFormatters = map[events.EventType]EventFormatter{
 	events.EventRunPromptStarted:       formatRunPromptStarted,
+	events.EventClaudeSessionStarted:   formatClaudeSessionStarted,
 	events.EventClaudeAssistantMessage: formatClaudeAssistantMessage,
+	events.EventClaudeThinking:         formatClaudeThinking,
 	events.EventClaudeToolUse:          formatClaudeToolUse,
 	events.EventClaudeToolResult:       formatClaudeToolResult,
 	events.EventClaudeExecutionResult:  formatClaudeExecutionResult,
@@ -30,21 +33,33 @@ var eventFormatters = map[events.EventType]EventFormatter{
 	events.EventLoopInterrupted:        formatLoopInterrupted,
 	events.EventSleepStarted:           formatSleepStarted,
 	events.EventImprovementStarted:     formatImprovementStarted,
+	events.EventCrossoverStarted:       formatCrossoverStarted,
+	events.EventCandidateCommitted:     formatCandidateCommitted,
+	events.EventChallengerFailed:       formatChallengerFailed,
 	events.EventComparisonStarted:      formatComparisonStarted,
 	events.EventComparisonRetry:        formatComparisonRetry,
 	events.EventWinnerSelected:         formatWinnerSelected,
+	events.EventEvolveFinished:         formatEvolveFinished,
 	events.EventEvolveCompleted:        formatEvolveCompleted,
 	events.EventEvolveInterrupted:      formatEvolveInterrupted,
+	events.EventEvolveCleanup:          formatEvolveCleanup,
 	events.EventGitBranchCreated:       formatGitBranchCreated,
 	events.EventGitBranchCheckedOut:    formatGitBranchCheckedOut,
 	events.EventGitBranchDeleted:       formatGitBranchDeleted,
+	events.EventGitBranchRenamed:       formatGitBranchRenamed,
+	events.EventGitWorktreeCreated:     formatGitWorktreeCreated,
 	events.EventGitCommitsSquashed:     formatGitCommitsSquashed,
+	events.EventGitStashed:             formatGitStashed,
+	events.EventGitStashRestored:       formatGitStashRestored,
+	events.EventRunStats:               formatRunStats,
 }
 
 // GetColorForEventType returns the ANSI color code for an event type
 func GetColorForEventType(eventType events.EventType) string {
 	switch eventType {
-	case events.EventRunPromptStarted:
+	case events.EventRunPromptStarted,
+		events.EventClaudeSessionStarted,
+		events.EventRunStats:
 		return BoldCyan
 
 	case events.EventLoopStarted,
@@ -52,30 +67,42 @@ func GetColorForEventType(eventType events.EventType) string {
 		events.EventEvolveStarted,
 		events.EventRoundStarted,
 		events.EventImprovementStarted,
+		events.EventCrossoverStarted,
 		events.EventComparisonStarted,
-		events.EventSleepStarted:
+		events.EventSleepStarted,
+		events.EventEvolveCleanup:
 		return BoldYellow
 
 	case events.EventClaudeExecutionResult,
 		events.EventLoopCompleted,
+		events.EventEvolveFinished,
 		events.EventEvolveCompleted,
 		events.EventIterationCompleted,
 		events.EventWinnerSelected:
 		return BoldGreen
 
 	case events.EventIterationFailed,
+		events.EventChallengerFailed,
 		events.EventLoopInterrupted,
 		events.EventEvolveInterrupted:
 		return BoldRed
 
 	case events.EventClaudeAssistantMessage,
+		events.EventCandidateCommitted,
 		events.EventComparisonRetry,
 		events.EventGitBranchCreated,
 		events.EventGitBranchCheckedOut,
 		events.EventGitBranchDeleted,
-		events.EventGitCommitsSquashed:
+		events.EventGitBranchRenamed,
+		events.EventGitCommitsSquashed,
+		events.EventGitStashed,
+		events.EventGitStashRestored,
+		events.EventGitWorktreeCreated:
 		return Magenta
 
+	case events.EventClaudeThinking:
+		return Gray
+
 	case events.EventClaudeToolUse,
 		events.EventClaudeToolResult:
 		return ""
@@ -83,3 +110,19 @@ func GetColorForEventType(eventType events.EventType) string {
 		return ""
 	}
 }
+
+// parentToolUseID returns the Task tool call whose subagent produced the event, or ""
+func parentToolUseID(event events.Event) string {
+	switch data := event.Data.(type) {
+	case events.AssistantMessageData:
+		return data.ParentToolUseID
+	case events.ThinkingData:
+		return data.ParentToolUseID
+	case events.ToolUseData:
+		return data.ParentToolUseID
+	case events.ToolResultData:
+		return data.ParentToolUseID
+	default:
+		return ""
+	}
+}
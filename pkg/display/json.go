@@ -0,0 +1,40 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// JSONFormatter writes each event as one line of JSON (NDJSON):
+//
+//	{"type":"iteration_completed","timestamp":"2025-01-02T15:04:05.123Z","data":{"current":1,"total":3,"duration_ns":61000000000}}
+//
+// "type" is one of the events.EventType values and "data" holds the event's
+// data struct with the keys given by its json tags. Durations are integer
+// nanoseconds and errors are their message strings.
+type JSONFormatter struct {
+	encoder *json.Encoder
+}
+
+func NewJSONFormatter(writer io.Writer) *JSONFormatter {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	return &JSONFormatter{encoder: encoder}
+}
+
+var _ Formatter = (*JSONFormatter)(nil)
+
+// Format writes the event as a JSON line
+func (f *JSONFormatter) Format(event events.Event) error {
+	if err := f.encoder.Encode(event); err != nil {
+		return fmt.Errorf("failed to write event as JSON: %w", err)
+	}
+	return nil
+}
+
+func (f *JSONFormatter) Flush() error {
+	return nil
+}
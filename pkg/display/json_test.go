@@ -0,0 +1,53 @@
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewJSONFormatter(buf)
+	stamp := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	for _, event := range []events.Event{
+		{Type: events.EventIterationCompleted, Timestamp: stamp, Data: events.IterationCompletedData{Current: 1, Total: 3, Duration: time.Second}},
+		{Type: events.EventIterationFailed, Timestamp: stamp, Data: events.IterationFailedData{Current: 2, Total: 3, Error: errors.New("exit <1>")}},
+	} {
+		if err := formatter.Format(event); err != nil {
+			t.Fatalf("Format failed: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		`{"type":"iteration_completed","timestamp":"2025-01-02T15:04:05Z","data":{"current":1,"total":3,"duration_ns":1000000000}}`,
+		`{"type":"iteration_failed","timestamp":"2025-01-02T15:04:05Z","data":{"current":2,"total":3,"reason":"","error":"exit <1>"}}`,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines; want %d: %q", len(lines), len(want), buf.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %s; want %s", i, lines[i], want[i])
+		}
+		if !json.Valid([]byte(lines[i])) {
+			t.Errorf("line %d is not valid JSON", i)
+		}
+	}
+}
+
+func TestJSONFormatter_EveryEventDecodes(t *testing.T) {
+	for eventType := range eventFormatters {
+		line := []byte(`{"type":"` + string(eventType) + `","timestamp":"2025-01-02T15:04:05Z","data":{}}`)
+		if _, err := events.DecodeEvent(line); err != nil {
+			t.Errorf("DecodeEvent(%s) error: %v", eventType, err)
+		}
+	}
+}
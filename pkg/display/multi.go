@@ -0,0 +1,39 @@
+package display
+
+import (
+	"errors"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// MultiFormatter passes every event to several formatters, e.g. the console and a run log
+type MultiFormatter struct {
+	formatters []Formatter
+}
+
+func NewMultiFormatter(formatters ...Formatter) *MultiFormatter {
+	return &MultiFormatter{formatters: formatters}
+}
+
+var _ Formatter = (*MultiFormatter)(nil)
+
+// Format passes the event to each formatter, even when an earlier one fails
+func (m *MultiFormatter) Format(event events.Event) error {
+	var errs []error
+	for _, f := range m.formatters {
+		if err := f.Format(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *MultiFormatter) Flush() error {
+	var errs []error
+	for _, f := range m.formatters {
+		if err := f.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,155 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// MinSecretEnvLength is the shortest ANTHROPIC_* value masked wherever it appears
+const MinSecretEnvLength = 8
+
+// redaction replaces matches of a pattern
+type redaction struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// builtinRedactions mask common secret formats
+var builtinRedactions = []redaction{
+	// Private key blocks
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), RedactedText},
+	// Anthropic and OpenAI API keys
+	{regexp.MustCompile(`sk-(?:ant-)?[A-Za-z0-9_-]{20,}`), RedactedText},
+	// AWS access key IDs
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), RedactedText},
+	// GitHub tokens
+	{regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`), RedactedText},
+	// Slack tokens
+	{regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), RedactedText},
+	// Bearer tokens in headers
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]{16,}=*`), "${1}" + RedactedText},
+	// Assignments to variables named like secrets, e.g. API_KEY=... or "token": "..."
+	{regexp.MustCompile(`\b([A-Za-z0-9_]*(?i:api_?key|secret|token|password))(["']?\s*[=:]\s*["']?)([^\s"',]{8,})`), "${1}${2}" + RedactedText},
+}
+
+// Redactor masks likely secrets in event data before it is written anywhere
+type Redactor struct {
+	redactions []redaction
+}
+
+// NewRedactor creates a redactor using the built-in patterns, the values of
+// ANTHROPIC_* environment variables, and the given extra regexes
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{redactions: append([]redaction(nil), builtinRedactions...)}
+
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		// The base URL is shown on purpose and is not a credential
+		if !strings.HasPrefix(name, "ANTHROPIC_") || name == "ANTHROPIC_BASE_URL" || len(value) < MinSecretEnvLength {
+			continue
+		}
+		r.redactions = append(r.redactions, redaction{regexp.MustCompile(regexp.QuoteMeta(value)), RedactedText})
+	}
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+		}
+		r.redactions = append(r.redactions, redaction{re, RedactedText})
+	}
+
+	return r, nil
+}
+
+// Redact masks secrets in text
+func (r *Redactor) Redact(text string) string {
+	for _, red := range r.redactions {
+		text = red.re.ReplaceAllString(text, red.repl)
+	}
+	return text
+}
+
+// RedactEvent returns a copy of the event with secrets masked in every string
+// of its data, including map values, slices and error messages
+func (r *Redactor) RedactEvent(event events.Event) events.Event {
+	if event.Data != nil {
+		event.Data = r.redactValue(reflect.ValueOf(event.Data)).Interface()
+	}
+	return event
+}
+
+// redactedError is an error whose message had secrets masked
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// redactValue returns a copy of v with secrets masked. Pointers are left
+// alone so shared data is never modified.
+func (r *Redactor) redactValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(r.Redact(v.String())).Convert(v.Type())
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(r.redactValue(v.Field(i)))
+			}
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(r.redactValue(v.Index(i)))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), r.redactValue(iter.Value()))
+		}
+		return out
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		if v.Type() == errorType {
+			err := v.Interface().(error)
+			if msg := r.Redact(err.Error()); msg != err.Error() {
+				out.Set(reflect.ValueOf(&redactedError{msg: msg, err: err}))
+				return out
+			}
+			return v
+		}
+		out.Set(r.redactValue(v.Elem()))
+		return out
+
+	default:
+		return v
+	}
+}
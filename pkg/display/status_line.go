@@ -22,7 +22,7 @@ type StatusLineFormatter struct {
 	enabled       bool
 	isTTY         bool
 	terminalWidth int
-	gitClient     *git.Client
+	gitClient     git.Repo
 
 	// Status block state
 	statusVisible bool
@@ -35,12 +35,13 @@ type StatusLineFormatter struct {
 	cwd          string
 	branch       string
 	baseURL      string
+	model        string
 	prompt       string
 	startTime    time.Time
 }
 
 // NewStatusLineFormatter creates a new status line formatter
-func NewStatusLineFormatter(wrapped Formatter, writer io.Writer, enabled bool, gitClient *git.Client) *StatusLineFormatter {
+func NewStatusLineFormatter(wrapped Formatter, writer io.Writer, enabled bool, gitClient git.Repo) *StatusLineFormatter {
 	f := &StatusLineFormatter{
 		wrapped:     wrapped,
 		writer:      writer,
@@ -124,6 +125,11 @@ func (f *StatusLineFormatter) updateState(event events.Event) {
 			f.prompt = strings.TrimSpace(data.Prompt)
 		}
 
+	case events.EventClaudeSessionStarted:
+		if data, ok := event.Data.(events.SessionStartedData); ok {
+			f.model = data.Model
+		}
+
 	case events.EventLoopStarted:
 		if data, ok := event.Data.(events.LoopStartedData); ok {
 			f.mode = "loop"
@@ -161,6 +167,15 @@ func (f *StatusLineFormatter) updateState(event events.Event) {
 		if data, ok := event.Data.(events.BranchCheckedOutData); ok {
 			f.branch = data.BranchName
 		}
+
+	case events.EventGitWorktreeCreated:
+		if data, ok := event.Data.(events.WorktreeCreatedData); ok {
+			f.cwd = data.Path
+			f.branch = data.Branch
+			if f.branch == "" {
+				f.branch = data.Base
+			}
+		}
 	}
 }
 
@@ -200,11 +215,15 @@ func (f *StatusLineFormatter) buildStatusBlock() []string {
 		line2 = strings.Join(parts, ", ")
 	}
 
-	// Line 3: Base URL: https://example.org
-	line3 := ""
+	// Line 3: Model: claude-sonnet-4-5, Base URL: https://example.org
+	var line3Parts []string
+	if f.model != "" {
+		line3Parts = append(line3Parts, fmt.Sprintf("Model: %s", f.model))
+	}
 	if f.baseURL != "" {
-		line3 = fmt.Sprintf("Base URL: %s", f.baseURL)
+		line3Parts = append(line3Parts, fmt.Sprintf("Base URL: %s", f.baseURL))
 	}
+	line3 := strings.Join(line3Parts, ", ")
 
 	// Line 4: Prompt: "text..." (with literal \n instead of newlines)
 	line4 := ""
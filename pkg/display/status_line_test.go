@@ -274,6 +274,34 @@ func TestStatusLineFormatter_EventTracking(t *testing.T) {
 	if f.branch != "another-branch" {
 		t.Errorf("Expected branch to be another-branch, got %q", f.branch)
 	}
+
+	// Test ClaudeSessionStarted event
+	f.updateState(events.Event{
+		Type: events.EventClaudeSessionStarted,
+		Data: events.SessionStartedData{Model: "claude-sonnet-4-5"},
+	})
+
+	if f.model != "claude-sonnet-4-5" {
+		t.Errorf("Expected model to be updated, got %q", f.model)
+	}
+
+	// Test GitWorktreeCreated event
+	event6 := events.Event{
+		Type: events.EventGitWorktreeCreated,
+		Data: events.WorktreeCreatedData{
+			Path:   "/repo/.agent-exec/worktrees/run-1",
+			Branch: "agent-exec/run-1",
+			Base:   "HEAD",
+		},
+	}
+	f.updateState(event6)
+
+	if f.cwd != "/repo/.agent-exec/worktrees/run-1" {
+		t.Errorf("Expected cwd to be the worktree path, got %q", f.cwd)
+	}
+	if f.branch != "agent-exec/run-1" {
+		t.Errorf("Expected branch to be agent-exec/run-1, got %q", f.branch)
+	}
 }
 
 func TestStatusLineFormatter_ConcurrentAccess(t *testing.T) {
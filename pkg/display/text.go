@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/term"
 )
@@ -37,6 +38,22 @@ func GetTerminalWidth() int {
 	return width
 }
 
+// Truncate limits s to maxLen characters, marking truncation with "...". It
+// cuts between runes, so multibyte text stays valid UTF-8.
+func Truncate(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	keep := maxLen - 3
+	for i := range s {
+		if keep == 0 {
+			return s[:i] + "..."
+		}
+		keep--
+	}
+	return s
+}
+
 func (tf *PlainTextFormatter) IndentContent(content string) string {
 	if content == "" {
 		return content
@@ -268,3 +268,26 @@ func TestTextFormatter_FormatContentWithFrameAndColor(t *testing.T) {
 		}
 	})
 }
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   string
+	}{
+		{name: "fits", input: "abc", maxLen: 3, want: "abc"},
+		{name: "ascii", input: "abcdef", maxLen: 5, want: "ab..."},
+		{name: "multibyte fits", input: "修复错误", maxLen: 4, want: "修复错误"},
+		{name: "cjk", input: "修复了解析器中的错误", maxLen: 6, want: "修复了..."},
+		{name: "emoji", input: "🐍🐍🐍🐍🐍", maxLen: 4, want: "🐍..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.input, tt.maxLen); got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q; want %q", tt.input, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
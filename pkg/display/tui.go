@@ -0,0 +1,834 @@
+package display
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+	"golang.org/x/term"
+)
+
+// Terminal control sequences of the full-screen dashboard
+const (
+	enterScreen = "\033[?1049h\033[?25l\033[?7l" // Alternate screen, hide cursor, no line wrap
+	leaveScreen = "\033[?7h\033[?25h\033[?1049l"
+	cursorHome  = "\033[H"
+	clearLine   = "\033[K"
+	clearBelow  = "\033[J"
+)
+
+const (
+	maxStreamLines  = 500
+	maxHistoryLines = 5000
+	tuiRefresh      = time.Second
+	keyPoll         = 50 * time.Millisecond // How often the key reader checks for close
+)
+
+// streamEvents are shown in full in the stream pane
+var streamEvents = map[events.EventType]bool{
+	events.EventRunPromptStarted:       true,
+	events.EventClaudeSessionStarted:   true,
+	events.EventClaudeAssistantMessage: true,
+	events.EventClaudeThinking:         true,
+	events.EventClaudeToolUse:          true,
+	events.EventClaudeToolResult:       true,
+	events.EventClaudeExecutionResult:  true,
+}
+
+// finalEvents are printed again after the dashboard closes, since the
+// alternate screen takes everything else with it
+var finalEvents = map[events.EventType]bool{
+	events.EventLoopCompleted:     true,
+	events.EventLoopInterrupted:   true,
+	events.EventEvolveFinished:    true,
+	events.EventEvolveInterrupted: true,
+	events.EventEvolveCleanup:     true,
+	events.EventEvolveCompleted:   true,
+	events.EventRunStats:          true,
+}
+
+// RunController is steered by the dashboard's keys; *claude.Control implements it
+type RunController interface {
+	TogglePause() bool
+	SkipSleep()
+	Abort()
+}
+
+// TUIOption configures a TUIFormatter
+type TUIOption func(*TUIFormatter)
+
+// WithController lets the dashboard's keys pause, skip sleeps and abort the run
+func WithController(controller RunController) TUIOption {
+	return func(f *TUIFormatter) {
+		f.controller = controller
+	}
+}
+
+// WithCostLimit shows spending against the run's budget
+func WithCostLimit(maxUSD float64) TUIOption {
+	return func(f *TUIFormatter) {
+		f.costLimit = maxUSD
+	}
+}
+
+// WithConsoleOptions configures how events are rendered in the stream and history panes
+func WithConsoleOptions(opts ...ConsoleOption) TUIOption {
+	return func(f *TUIFormatter) {
+		f.consoleOpts = append(f.consoleOpts, opts...)
+	}
+}
+
+// TUIFormatter shows a run as a full-screen dashboard: the live Claude
+// stream, a progress tree, the candidate leaderboard, cost and token meters
+// and a scrollable event history
+type TUIFormatter struct {
+	mu          sync.Mutex
+	in          *os.File
+	out         io.Writer
+	console     *ConsoleFormatter
+	consoleOpts []ConsoleOption
+	buf         bytes.Buffer
+	controller  RunController
+	costLimit   float64
+
+	width    int
+	height   int
+	live     bool // The alternate screen is shown
+	oldState *term.State
+	done     chan struct{}
+	keysDone chan struct{} // Closed when readKeys has stopped
+	closed   bool
+
+	// Dashboard state
+	mode       string // "loop" or "evolve"
+	current    int
+	total      int
+	started    time.Time
+	paused     bool
+	aborting   bool
+	sleepUntil time.Time
+	stream     []string
+	history    []string
+	scroll     int // History lines scrolled back from the newest
+	progress   []*progressSection
+	candidates []*candidate
+	winner     string
+	costUSD    float64
+	tokensIn   int
+	tokensOut  int
+	calls      int
+	final      strings.Builder
+}
+
+// progressSection is an iteration, round or the initial implementation in the progress tree
+type progressSection struct {
+	title  string
+	status string
+	steps  []string
+}
+
+// candidate is a row of the leaderboard
+type candidate struct {
+	branch  string
+	round   int
+	subject string
+	costUSD float64
+	wins    int
+	out     bool   // Eliminated by the judge
+	failed  string // Why the challenger failed before it was judged
+}
+
+var _ Formatter = (*TUIFormatter)(nil)
+
+// NewTUIFormatter creates a dashboard on the terminal of in and out. It takes
+// over the terminal with the first event, switching out to the alternate
+// screen and reading keys from in until Flush.
+func NewTUIFormatter(in, out *os.File, verbose bool, opts ...TUIOption) (*TUIFormatter, error) {
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return nil, errors.New("the dashboard needs an interactive terminal")
+	}
+	width, height, err := term.GetSize(int(out.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get terminal size: %w", err)
+	}
+
+	f := newTUIFormatter(out, verbose, width, height, opts...)
+	f.in = in
+	return f, nil
+}
+
+// newTUIFormatter creates a dashboard of the given size that is not attached to a terminal
+func newTUIFormatter(out io.Writer, verbose bool, width, height int, opts ...TUIOption) *TUIFormatter {
+	f := &TUIFormatter{
+		out:     out,
+		width:   width,
+		height:  height,
+		started: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	f.console = NewConsoleFormatter(&f.buf, verbose, f.consoleOpts...)
+	return f
+}
+
+// start switches to the alternate screen and starts reading keys
+func (f *TUIFormatter) start() error {
+	oldState, err := term.MakeRaw(int(f.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set up terminal: %w", err)
+	}
+	f.oldState = oldState
+	f.done = make(chan struct{})
+	f.keysDone = make(chan struct{})
+	f.live = true
+	_, _ = io.WriteString(f.out, enterScreen)
+
+	go f.readKeys()
+	go f.refresh()
+	return nil
+}
+
+// Format renders the event into the dashboard's panes and redraws it
+func (f *TUIFormatter) Format(event events.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.in != nil && !f.live && !f.closed {
+		if err := f.start(); err != nil {
+			f.in = nil // Report it once; the closing output is still printed
+			return err
+		}
+	}
+
+	f.buf.Reset()
+	if err := f.console.Format(event); err != nil {
+		return err
+	}
+	f.update(event, f.buf.String())
+	f.draw()
+	return nil
+}
+
+// Flush restores the terminal and prints the run's closing output
+func (f *TUIFormatter) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	if f.live {
+		// Let the key reader stop before the terminal goes back to cooked
+		// mode, so it does not take input meant for whatever reads next
+		close(f.done)
+		f.mu.Unlock()
+		select {
+		case <-f.keysDone:
+		case <-time.After(2 * keyPoll):
+		}
+		f.mu.Lock()
+
+		_, _ = io.WriteString(f.out, leaveScreen)
+		if err := term.Restore(int(f.in.Fd()), f.oldState); err != nil {
+			return fmt.Errorf("failed to restore terminal: %w", err)
+		}
+		f.live = false
+	}
+
+	_, err := io.WriteString(f.out, f.final.String())
+	return err
+}
+
+// update records an event and its console output in the dashboard state
+func (f *TUIFormatter) update(event events.Event, text string) {
+	text = strings.NewReplacer("\t", "    ", "\r", "").Replace(text)
+
+	if streamEvents[event.Type] {
+		f.stream = appendLines(f.stream, strings.Split(strings.TrimRight(text, "\n"), "\n"), maxStreamLines)
+	}
+	if line := firstLine(text); line != "" {
+		f.history = appendLines(f.history, []string{line}, maxHistoryLines)
+		if f.scroll > 0 {
+			f.scroll++
+		}
+	}
+	if finalEvents[event.Type] {
+		f.final.WriteString(text)
+	}
+
+	if event.Type != events.EventSleepStarted {
+		f.sleepUntil = time.Time{}
+	}
+
+	switch data := event.Data.(type) {
+	case events.LoopStartedData:
+		f.mode = "loop"
+		f.total = data.TotalIterations
+	case events.EvolveStartedData:
+		f.mode = "evolve"
+		f.total = data.TotalIterations
+		f.startSection("Initial implementation")
+	case events.IterationStartedData:
+		f.mode = "loop"
+		f.current, f.total = data.Current, data.Total
+		f.startSection(fmt.Sprintf("Iteration %d/%d", data.Current, data.Total))
+	case events.IterationCompletedData:
+		f.setStatus("done in " + formatDuration(data.Duration))
+	case events.IterationFailedData:
+		f.setStatus("failed")
+	case events.RoundStartedData:
+		f.mode = "evolve"
+		f.current, f.total = data.Round, data.Total
+		f.startSection(fmt.Sprintf("Round %d/%d", data.Round, data.Total))
+
+	case events.ImprovementStartedData:
+		f.addStep("improve -> " + data.BranchName)
+	case events.CrossoverStartedData:
+		f.addStep(fmt.Sprintf("crossover %s + %s -> %s", data.Parent1, data.Parent2, data.BranchName))
+	case events.ComparisonStartedData:
+		f.addStep(fmt.Sprintf("compare %s vs %s", data.Branch1, data.Branch2))
+	case events.CandidateCommittedData:
+		f.candidates = append(f.candidates, &candidate{
+			branch:  data.BranchName,
+			round:   data.Round,
+			subject: data.Subject,
+			costUSD: data.CostUSD,
+		})
+		if data.Round == 0 {
+			f.winner = data.BranchName
+		}
+	case events.WinnerSelectedData:
+		f.addStep(fmt.Sprintf("kept %s over %s", data.Winner, data.Loser))
+		f.setStatus("done")
+		f.winner = data.Winner
+		if c := f.candidate(data.Winner); c != nil {
+			c.wins++
+		}
+		if c := f.candidate(data.Loser); c != nil {
+			c.out = true
+		}
+	case events.ChallengerFailedData:
+		f.addStep(fmt.Sprintf("%s failed (%s)", data.BranchName, data.Reason))
+		f.candidates = append(f.candidates, &candidate{branch: data.BranchName, round: data.Round, failed: data.Reason})
+
+	case events.RunPromptStartedData:
+		f.calls++
+	case events.ExecutionResultData:
+		f.costUSD += data.CostUSD
+		f.tokensIn += data.InputTokens
+		f.tokensOut += data.OutputTokens
+	case events.SleepStartedData:
+		f.sleepUntil = event.Timestamp.Add(data.Duration)
+
+	case events.LoopCompletedData, events.EvolveCompletedData:
+		f.endSection("done")
+	case events.LoopInterruptedData, events.EvolveInterruptedData:
+		f.endSection("interrupted")
+	}
+}
+
+func (f *TUIFormatter) startSection(title string) {
+	f.endSection("done")
+	f.progress = append(f.progress, &progressSection{title: title, status: "running"})
+}
+
+func (f *TUIFormatter) setStatus(status string) {
+	if len(f.progress) > 0 {
+		f.progress[len(f.progress)-1].status = status
+	}
+}
+
+// endSection sets the status of the last section unless it already ended
+func (f *TUIFormatter) endSection(status string) {
+	if len(f.progress) > 0 {
+		if last := f.progress[len(f.progress)-1]; last.status == "running" {
+			last.status = status
+		}
+	}
+}
+
+func (f *TUIFormatter) addStep(step string) {
+	if len(f.progress) == 0 {
+		f.startSection("Run")
+	}
+	section := f.progress[len(f.progress)-1]
+	section.steps = append(section.steps, step)
+}
+
+func (f *TUIFormatter) candidate(branch string) *candidate {
+	for _, c := range f.candidates {
+		if c.branch == branch {
+			return c
+		}
+	}
+	return nil
+}
+
+// handleInput acts on keys read from the terminal, ignoring any that arrive
+// after the dashboard closed
+func (f *TUIFormatter) handleInput(input []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+
+	for _, key := range splitKeys(string(input)) {
+		switch key {
+		case "p", " ":
+			if f.controller != nil && !f.aborting {
+				f.paused = f.controller.TogglePause()
+			}
+		case "s":
+			if f.controller != nil && !f.sleepUntil.IsZero() {
+				f.controller.SkipSleep()
+			}
+		case "q", "\x03":
+			if f.controller != nil && !f.aborting {
+				f.aborting = true
+				f.controller.Abort()
+			}
+		case "k", "\x1b[A":
+			f.scrollHistory(1)
+		case "j", "\x1b[B":
+			f.scrollHistory(-1)
+		case "\x1b[5~":
+			f.scrollHistory(f.historyHeight())
+		case "\x1b[6~":
+			f.scrollHistory(-f.historyHeight())
+		case "g", "\x1b[H", "\x1b[1~":
+			f.scrollHistory(len(f.history))
+		case "G", "\x1b[F", "\x1b[4~":
+			f.scroll = 0
+		}
+	}
+	f.draw()
+}
+
+func (f *TUIFormatter) scrollHistory(lines int) {
+	f.scroll += lines
+	if maxScroll := len(f.history) - f.historyHeight(); f.scroll > maxScroll {
+		f.scroll = maxScroll
+	}
+	if f.scroll < 0 {
+		f.scroll = 0
+	}
+}
+
+// splitKeys splits terminal input into keys, keeping escape sequences together
+func splitKeys(input string) []string {
+	var keys []string
+	for len(input) > 0 {
+		end := 1
+		if strings.HasPrefix(input, "\x1b[") {
+			end = escapeEnd(input, 0)
+		} else {
+			_, end = utf8.DecodeRuneInString(input)
+		}
+		keys = append(keys, input[:end])
+		input = input[end:]
+	}
+	return keys
+}
+
+// readKeys feeds terminal input to handleInput until the dashboard closes.
+// It only reads once input is waiting, so it stops within keyPoll of the
+// close instead of blocking in Read.
+func (f *TUIFormatter) readKeys() {
+	defer close(f.keysDone)
+	buf := make([]byte, 64)
+	for {
+		select {
+		case <-f.done:
+			return
+		default:
+		}
+		ready, err := waitForInput(f.in, keyPoll)
+		if err != nil {
+			return
+		}
+		if !ready {
+			continue
+		}
+		n, err := f.in.Read(buf)
+		if err != nil {
+			return
+		}
+		f.handleInput(buf[:n])
+	}
+}
+
+// refresh redraws the dashboard every second for the clocks and terminal resizes
+func (f *TUIFormatter) refresh() {
+	ticker := time.NewTicker(tuiRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-ticker.C:
+			f.mu.Lock()
+			if file, ok := f.out.(*os.File); ok {
+				if width, height, err := term.GetSize(int(file.Fd())); err == nil {
+					f.width, f.height = width, height
+				}
+			}
+			f.draw()
+			f.mu.Unlock()
+		}
+	}
+}
+
+// draw writes the whole dashboard over the alternate screen
+func (f *TUIFormatter) draw() {
+	if !f.live {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(cursorHome)
+	for i, line := range f.render() {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(clip(line, f.width))
+		b.WriteString(clearLine)
+	}
+	b.WriteString(clearBelow)
+	_, _ = io.WriteString(f.out, b.String())
+}
+
+// Pane heights for the current terminal size
+func (f *TUIFormatter) topHeight() int {
+	return clampInt((f.height-2)/3, 4, 12)
+}
+
+func (f *TUIFormatter) streamHeight() int {
+	return (f.height - 2 - f.topHeight()) * 3 / 5
+}
+
+func (f *TUIFormatter) historyHeight() int {
+	return max(f.height-2-f.topHeight()-f.streamHeight()-2, 1)
+}
+
+// render lays out the dashboard as one string per terminal line
+func (f *TUIFormatter) render() []string {
+	lines := []string{f.header()}
+
+	// Progress, leaderboard and meters side by side
+	topHeight := f.topHeight()
+	third := (f.width - 6) / 3
+	columns := [][]string{
+		f.progressPane(topHeight - 1),
+		f.leaderboardPane(topHeight - 1),
+		f.metersPane(),
+	}
+	titles := []string{"Progress", "Leaderboard", "Meters"}
+	row := make([]string, len(columns))
+	for i, title := range titles {
+		row[i] = pad(Bold+title+Reset, third)
+	}
+	lines = append(lines, strings.Join(row, " │ "))
+	for i := 0; i < topHeight-1; i++ {
+		for c, column := range columns {
+			cell := ""
+			if i < len(column) {
+				cell = column[i]
+			}
+			row[c] = pad(cell, third)
+		}
+		lines = append(lines, strings.Join(row, " │ "))
+	}
+
+	// Live stream
+	streamHeight := f.streamHeight()
+	lines = append(lines, Bold+"Stream "+strings.Repeat("─", max(f.width-7, 0))+Reset)
+	lines = append(lines, tail(f.stream, streamHeight-1, 0)...)
+	for len(lines) < 1+topHeight+streamHeight {
+		lines = append(lines, "")
+	}
+
+	// Event history
+	historyHeight := f.historyHeight()
+	title := "History "
+	if f.scroll > 0 {
+		title = fmt.Sprintf("History (%d newer) ", f.scroll)
+	}
+	lines = append(lines, Bold+title+strings.Repeat("─", max(f.width-visibleWidth(title), 0))+Reset)
+	lines = append(lines, tail(f.history, historyHeight, f.scroll)...)
+	for len(lines) < f.height-1 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, Gray+"p pause/resume · s skip sleep · q abort · ↑/↓ PgUp/PgDn Home/End scroll history"+Reset)
+	return lines
+}
+
+// header is the top line: mode, progress, elapsed time and run state
+func (f *TUIFormatter) header() string {
+	parts := []string{"agent-exec"}
+	if f.mode != "" {
+		parts[0] += " " + f.mode
+	}
+	if f.total > 0 {
+		unit := "Iteration"
+		if f.mode == "evolve" {
+			unit = "Round"
+		}
+		parts = append(parts, fmt.Sprintf("%s %d/%d", unit, f.current, f.total))
+	}
+	parts = append(parts, formatDuration(time.Since(f.started)), fmt.Sprintf("$%.4f", f.costUSD))
+
+	state := "running"
+	switch {
+	case f.aborting:
+		state = "aborting after the current step"
+	case f.paused:
+		state = "paused after the current step"
+	case !f.sleepUntil.IsZero():
+		state = "sleeping"
+	}
+	parts = append(parts, state)
+
+	header := " " + strings.Join(parts, " │ ")
+	return ReverseVideo + pad(header, f.width) + Reset
+}
+
+// progressPane lists the latest sections of the run with their steps
+func (f *TUIFormatter) progressPane(height int) []string {
+	var lines []string
+	for _, section := range f.progress {
+		marker := "✓"
+		switch section.status {
+		case "running":
+			marker = "▸"
+		case "failed", "interrupted":
+			marker = "✗"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s (%s)", marker, section.title, section.status))
+		for _, step := range section.steps {
+			lines = append(lines, "   "+step)
+		}
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "waiting for the run to start")
+	}
+	return tail(lines, height, 0)
+}
+
+// leaderboardPane ranks the candidates: the winner, then survivors by wins,
+// then eliminated and failed candidates
+func (f *TUIFormatter) leaderboardPane(height int) []string {
+	if f.mode != "evolve" {
+		var done, failed int
+		for _, section := range f.progress {
+			switch {
+			case strings.HasPrefix(section.status, "done"):
+				done++
+			case section.status == "failed":
+				failed++
+			}
+		}
+		return []string{fmt.Sprintf("%d iteration(s) done, %d failed", done, failed)}
+	}
+
+	ranked := append([]*candidate(nil), f.candidates...)
+	rank := func(c *candidate) int {
+		switch {
+		case c.branch == f.winner:
+			return 0
+		case c.failed == "" && !c.out:
+			return 1
+		case c.out:
+			return 2
+		default:
+			return 3
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if rank(ranked[i]) != rank(ranked[j]) {
+			return rank(ranked[i]) < rank(ranked[j])
+		}
+		return ranked[i].wins > ranked[j].wins
+	})
+
+	var lines []string
+	for _, c := range ranked {
+		marker := " "
+		switch rank(c) {
+		case 0:
+			marker = "★"
+		case 2, 3:
+			marker = "✗"
+		}
+		detail := c.subject
+		if c.failed != "" {
+			detail = "failed: " + c.failed
+		}
+		lines = append(lines, fmt.Sprintf("%s %s r%d %dW $%.2f %s", marker, c.branch, c.round, c.wins, c.costUSD, detail))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "no candidates yet")
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lines
+}
+
+// metersPane shows cost, tokens and timing
+func (f *TUIFormatter) metersPane() []string {
+	cost := fmt.Sprintf("Cost    $%.4f", f.costUSD)
+	var lines []string
+	if f.costLimit > 0 {
+		cost += fmt.Sprintf(" of $%.2f", f.costLimit)
+		lines = append(lines, cost, "        "+meter(f.costUSD/f.costLimit, 20))
+	} else {
+		lines = append(lines, cost)
+	}
+	lines = append(lines,
+		fmt.Sprintf("Tokens  %s in, %s out", formatCount(f.tokensIn), formatCount(f.tokensOut)),
+		fmt.Sprintf("Claude  %d call(s)", f.calls),
+		fmt.Sprintf("Elapsed %s", formatDuration(time.Since(f.started))),
+	)
+	if !f.sleepUntil.IsZero() {
+		lines = append(lines, fmt.Sprintf("Sleep   %s left", formatDuration(time.Until(f.sleepUntil))))
+	}
+	return lines
+}
+
+// meter draws a bar filled to fraction
+func meter(fraction float64, width int) string {
+	filled := int(fraction*float64(width) + 0.5)
+	filled = clampInt(filled, 0, width)
+	return fmt.Sprintf("[%s%s] %d%%", strings.Repeat("█", filled), strings.Repeat("░", width-filled), int(fraction*100))
+}
+
+// formatCount shortens large token counts, e.g. 12345 to "12.3k"
+func formatCount(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// appendLines appends lines, dropping the oldest beyond limit
+func appendLines(buf, lines []string, limit int) []string {
+	buf = append(buf, lines...)
+	if len(buf) > limit {
+		buf = append(buf[:0], buf[len(buf)-limit:]...)
+	}
+	return buf
+}
+
+// tail returns up to n lines ending skip lines before the end
+func tail(lines []string, n, skip int) []string {
+	end := max(len(lines)-skip, 0)
+	start := max(end-n, 0)
+	return lines[start:end]
+}
+
+// firstLine returns the first non-blank line of text
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+func clampInt(n, lo, hi int) int {
+	return min(max(n, lo), hi)
+}
+
+// clip cuts line to width terminal columns, keeping its color escapes
+func clip(line string, width int) string {
+	var b strings.Builder
+	col := 0
+	escaped := false
+	for i := 0; i < len(line); {
+		if line[i] == '\033' {
+			end := escapeEnd(line, i)
+			b.WriteString(line[i:end])
+			escaped = true
+			i = end
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		w := runeWidth(r)
+		if col+w > width {
+			break
+		}
+		b.WriteString(line[i : i+size])
+		col += w
+		i += size
+	}
+	if escaped {
+		b.WriteString(Reset)
+	}
+	return b.String()
+}
+
+// pad clips s to width columns and fills the rest with spaces
+func pad(s string, width int) string {
+	s = clip(s, width)
+	return s + strings.Repeat(" ", max(width-visibleWidth(s), 0))
+}
+
+// visibleWidth counts the terminal columns of s, ignoring escape sequences
+func visibleWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			i = escapeEnd(s, i)
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += runeWidth(r)
+		i += size
+	}
+	return width
+}
+
+// escapeEnd returns the index just past the escape sequence starting at i
+func escapeEnd(s string, i int) int {
+	if i+1 >= len(s) || s[i+1] != '[' {
+		return min(i+2, len(s))
+	}
+	for j := i + 2; j < len(s); j++ {
+		if s[j] >= 0x40 && s[j] <= 0x7e {
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+// runeWidth approximates the terminal columns of r: two for emoji and East
+// Asian wide characters, none for joiners and combining marks
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20, r == 0x200d, r >= 0xfe00 && r <= 0xfe0f, r >= 0x300 && r <= 0x36f:
+		return 0
+	case r >= 0x1100 && r <= 0x115f, r >= 0x2e80 && r <= 0xa4cf, r >= 0xac00 && r <= 0xd7a3,
+		r >= 0xf900 && r <= 0xfaff, r >= 0xff00 && r <= 0xff60, r >= 0xffe0 && r <= 0xffe6,
+		r >= 0x1f300 && r <= 0x1faff, r >= 0x20000 && r <= 0x3fffd,
+		r >= 0x23e9 && r <= 0x23fa, r == 0x2705, r == 0x274c, r >= 0x2753 && r <= 0x2757, r == 0x2b50:
+		return 2
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package display
+
+import (
+	"os"
+	"time"
+)
+
+// waitForInput cannot poll the terminal here, so readKeys blocks in Read and
+// Flush stops waiting for it after a short timeout
+func waitForInput(in *os.File, timeout time.Duration) (bool, error) {
+	return true, nil
+}
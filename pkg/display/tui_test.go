@@ -0,0 +1,199 @@
+package display
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// fakeController records the keys acting on the run
+type fakeController struct {
+	paused  bool
+	skips   int
+	aborted int
+}
+
+func (c *fakeController) TogglePause() bool {
+	c.paused = !c.paused
+	return c.paused
+}
+
+func (c *fakeController) SkipSleep() { c.skips++ }
+
+func (c *fakeController) Abort() { c.aborted++ }
+
+// formatAll feeds events to the dashboard
+func formatAll(t *testing.T, f *TUIFormatter, evs ...events.Event) {
+	t.Helper()
+	for _, event := range evs {
+		if err := f.Format(event); err != nil {
+			t.Fatalf("Format(%s) failed: %v", event.Type, err)
+		}
+	}
+}
+
+func tuiEvent(eventType events.EventType, data interface{}) events.Event {
+	return events.Event{Type: eventType, Timestamp: time.Now(), Data: data}
+}
+
+func TestTUIFormatter_Render(t *testing.T) {
+	f := newTUIFormatter(&bytes.Buffer{}, false, 120, 30, WithCostLimit(2))
+	formatAll(t, f,
+		tuiEvent(events.EventEvolveStarted, events.EvolveStartedData{TotalIterations: 2, RunID: "run-1"}),
+		tuiEvent(events.EventClaudeAssistantMessage, events.AssistantMessageData{Text: "writing the game"}),
+		tuiEvent(events.EventClaudeExecutionResult, events.ExecutionResultData{Duration: time.Second, CostUSD: 0.5, InputTokens: 12345, OutputTokens: 678}),
+		tuiEvent(events.EventCandidateCommitted, events.CandidateCommittedData{BranchName: "impl-aaa", Subject: "Add snake", CostUSD: 0.5}),
+		tuiEvent(events.EventRoundStarted, events.RoundStartedData{Round: 1, Total: 2}),
+		tuiEvent(events.EventImprovementStarted, events.ImprovementStartedData{BranchName: "impl-bbb"}),
+		tuiEvent(events.EventCandidateCommitted, events.CandidateCommittedData{BranchName: "impl-bbb", Round: 1, Subject: "Add tests"}),
+		tuiEvent(events.EventWinnerSelected, events.WinnerSelectedData{Winner: "impl-bbb", Loser: "impl-aaa"}),
+	)
+
+	lines := f.render()
+	if len(lines) != 30 {
+		t.Errorf("got %d lines; want the terminal height 30", len(lines))
+	}
+	screen := stripANSI(strings.Join(lines, "\n"))
+	for _, want := range []string{
+		"agent-exec evolve │ Round 1/2",
+		"✓ Initial implementation (done)",
+		"✓ Round 1/2 (done)",
+		"kept impl-bbb over impl-aaa",
+		"★ impl-bbb r1 1W",
+		"✗ impl-aaa r0 0W",
+		"Cost    $0.5000 of $2.00",
+		"Tokens  12.3k in, 678 out",
+		"writing the game",
+	} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen missing %q:\n%s", want, screen)
+		}
+	}
+	for i, line := range lines {
+		if width := visibleWidth(line); width > 120 {
+			t.Errorf("line %d is %d columns wide; want at most 120", i, width)
+		}
+	}
+}
+
+func TestTUIFormatter_Keys(t *testing.T) {
+	controller := &fakeController{}
+	f := newTUIFormatter(&bytes.Buffer{}, false, 100, 30, WithController(controller))
+
+	f.handleInput([]byte("p"))
+	if !controller.paused || !f.paused {
+		t.Error("p did not pause the run")
+	}
+	if header := stripANSI(f.header()); !strings.Contains(header, "paused after the current step") {
+		t.Errorf("header = %q; want the paused state", header)
+	}
+	f.handleInput([]byte("p"))
+	if controller.paused || f.paused {
+		t.Error("second p did not resume the run")
+	}
+
+	// Skipping only applies while sleeping
+	f.handleInput([]byte("s"))
+	formatAll(t, f, tuiEvent(events.EventSleepStarted, events.SleepStartedData{Duration: time.Minute}))
+	f.handleInput([]byte("s"))
+	if controller.skips != 1 {
+		t.Errorf("got %d skips; want 1", controller.skips)
+	}
+
+	f.handleInput([]byte("q\x03"))
+	if controller.aborted != 1 || !f.aborting {
+		t.Errorf("got %d aborts; want 1", controller.aborted)
+	}
+}
+
+func TestTUIFormatter_KeysIgnoredAfterFlush(t *testing.T) {
+	controller := &fakeController{}
+	f := newTUIFormatter(&bytes.Buffer{}, false, 100, 30, WithController(controller))
+	if err := f.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	f.handleInput([]byte("pq"))
+	if controller.paused || controller.aborted != 0 {
+		t.Errorf("got paused %v, %d aborts after Flush; want the keys ignored", controller.paused, controller.aborted)
+	}
+}
+
+func TestTUIFormatter_ScrollHistory(t *testing.T) {
+	f := newTUIFormatter(&bytes.Buffer{}, false, 100, 30)
+	for i := 0; i < 50; i++ {
+		formatAll(t, f, tuiEvent(events.EventClaudeAssistantMessage, events.AssistantMessageData{Text: "message"}))
+	}
+	height := f.historyHeight()
+
+	f.handleInput([]byte("\x1b[A\x1b[A"))
+	if f.scroll != 2 {
+		t.Errorf("scroll = %d after two up arrows; want 2", f.scroll)
+	}
+	formatAll(t, f, tuiEvent(events.EventClaudeAssistantMessage, events.AssistantMessageData{Text: "newest"}))
+	if f.scroll != 3 {
+		t.Errorf("scroll = %d after a new event; want 3 to keep the view still", f.scroll)
+	}
+	f.handleInput([]byte("g"))
+	if f.scroll != len(f.history)-height {
+		t.Errorf("scroll = %d after g; want %d", f.scroll, len(f.history)-height)
+	}
+	f.handleInput([]byte("G"))
+	if f.scroll != 0 {
+		t.Errorf("scroll = %d after G; want 0", f.scroll)
+	}
+}
+
+func TestTUIFormatter_FlushPrintsClosingOutput(t *testing.T) {
+	var buf bytes.Buffer
+	f := newTUIFormatter(&buf, false, 100, 30)
+	formatAll(t, f,
+		tuiEvent(events.EventLoopStarted, events.LoopStartedData{TotalIterations: 1}),
+		tuiEvent(events.EventLoopCompleted, events.LoopCompletedData{TotalIterations: 1, SuccessfulIterations: 1}),
+	)
+	if buf.Len() != 0 {
+		t.Errorf("got output %q before Flush; want none off-terminal", buf.String())
+	}
+
+	if err := f.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	out := stripANSI(buf.String())
+	if strings.Contains(out, "Iterations:") || !strings.Contains(out, "Loop completed: 1/1 successful") {
+		t.Errorf("got %q; want only the completion summary", out)
+	}
+}
+
+func TestClip(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  string
+	}{
+		{name: "short", line: "abc", width: 5, want: "abc"},
+		{name: "cut", line: "abcdef", width: 3, want: "abc"},
+		{name: "colors kept", line: Red + "abcdef" + Reset, width: 2, want: Red + "ab" + Reset},
+		{name: "wide emoji", line: "❌ab", width: 3, want: "❌a"},
+		{name: "wide does not fit", line: "a❌", width: 2, want: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clip(tt.line, tt.width); got != tt.want {
+				t.Errorf("clip(%q, %d) = %q; want %q", tt.line, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitKeys(t *testing.T) {
+	got := splitKeys("p\x1b[A\x1b[5~q")
+	want := []string{"p", "\x1b[A", "\x1b[5~", "q"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitKeys = %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+//go:build unix
+
+package display
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTUIFormatter_ReadKeysStopsOnClose(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	controller := &fakeController{}
+	f := newTUIFormatter(&bytes.Buffer{}, false, 100, 30, WithController(controller))
+	f.in = r
+	f.done = make(chan struct{})
+	f.keysDone = make(chan struct{})
+	go f.readKeys()
+
+	if _, err := w.Write([]byte("p")); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(keyPoll) {
+		f.mu.Lock()
+		paused := f.paused
+		f.mu.Unlock()
+		if paused {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("p was not read before the close")
+		}
+	}
+
+	close(f.done)
+	select {
+	case <-f.keysDone:
+	case <-time.After(time.Second):
+		t.Fatal("readKeys did not stop after close")
+	}
+
+	// Input typed after the close is left for the next reader
+	if _, err := w.Write([]byte("q")); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	rest := make([]byte, 8)
+	n, _ := r.Read(rest)
+	if got := string(rest[:n]); got != "q" {
+		t.Errorf("next reader got %q; want the input after the close", got)
+	}
+	if controller.aborted != 0 {
+		t.Errorf("got %d aborts; want none after close", controller.aborted)
+	}
+}
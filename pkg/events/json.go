@@ -0,0 +1,112 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MarshalJSON writes the error as its message
+func (d IterationFailedData) MarshalJSON() ([]byte, error) {
+	type plain IterationFailedData
+	msg := ""
+	if d.Error != nil {
+		msg = d.Error.Error()
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(struct {
+		plain
+		Error string `json:"error"`
+	}{plain(d), msg})
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), err
+}
+
+// UnmarshalJSON restores the error from its message
+func (d *IterationFailedData) UnmarshalJSON(data []byte) error {
+	type plain IterationFailedData
+	aux := struct {
+		*plain
+		Error string `json:"error"`
+	}{plain: (*plain)(d)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	d.Error = nil
+	if aux.Error != "" {
+		d.Error = errors.New(aux.Error)
+	}
+	return nil
+}
+
+// dataDecoders decode the data of each event type written as JSON
+var dataDecoders = map[EventType]func(json.RawMessage) (interface{}, error){
+	EventRunPromptStarted:       decodeData[RunPromptStartedData],
+	EventClaudeSessionStarted:   decodeData[SessionStartedData],
+	EventClaudeAssistantMessage: decodeData[AssistantMessageData],
+	EventClaudeThinking:         decodeData[ThinkingData],
+	EventClaudeToolUse:          decodeData[ToolUseData],
+	EventClaudeToolResult:       decodeData[ToolResultData],
+	EventClaudeExecutionResult:  decodeData[ExecutionResultData],
+	EventGitBranchCreated:       decodeData[BranchCreatedData],
+	EventGitBranchCheckedOut:    decodeData[BranchCheckedOutData],
+	EventGitBranchDeleted:       decodeData[BranchDeletedData],
+	EventGitBranchRenamed:       decodeData[BranchRenamedData],
+	EventGitCommitsSquashed:     decodeData[CommitsSquashedData],
+	EventGitStashed:             decodeData[StashData],
+	EventGitStashRestored:       decodeData[StashData],
+	EventGitWorktreeCreated:     decodeData[WorktreeCreatedData],
+	EventLoopStarted:            decodeData[LoopStartedData],
+	EventIterationStarted:       decodeData[IterationStartedData],
+	EventIterationCompleted:     decodeData[IterationCompletedData],
+	EventIterationFailed:        decodeData[IterationFailedData],
+	EventLoopCompleted:          decodeData[LoopCompletedData],
+	EventLoopInterrupted:        decodeData[LoopInterruptedData],
+	EventEvolveStarted:          decodeData[EvolveStartedData],
+	EventRoundStarted:           decodeData[RoundStartedData],
+	EventImprovementStarted:     decodeData[ImprovementStartedData],
+	EventCrossoverStarted:       decodeData[CrossoverStartedData],
+	EventCandidateCommitted:     decodeData[CandidateCommittedData],
+	EventChallengerFailed:       decodeData[ChallengerFailedData],
+	EventComparisonStarted:      decodeData[ComparisonStartedData],
+	EventComparisonRetry:        decodeData[ComparisonRetryData],
+	EventWinnerSelected:         decodeData[WinnerSelectedData],
+	EventEvolveFinished:         decodeData[EvolveFinishedData],
+	EventEvolveCompleted:        decodeData[EvolveCompletedData],
+	EventEvolveInterrupted:      decodeData[EvolveInterruptedData],
+	EventEvolveCleanup:          decodeData[EvolveCleanupData],
+	EventSleepStarted:           decodeData[SleepStartedData],
+	EventRunStats:               decodeData[RunStatsData],
+}
+
+// decodeData decodes event data into a T
+func decodeData[T any](raw json.RawMessage) (interface{}, error) {
+	var data T
+	err := json.Unmarshal(raw, &data)
+	return data, err
+}
+
+// DecodeEvent parses one line written by display.JSONFormatter back into an event
+func DecodeEvent(line []byte) (Event, error) {
+	var raw struct {
+		Type      EventType       `json:"type"`
+		Timestamp time.Time       `json:"timestamp"`
+		Data      json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return Event{}, fmt.Errorf("invalid event: %w", err)
+	}
+
+	decode, ok := dataDecoders[raw.Type]
+	if !ok {
+		return Event{}, fmt.Errorf("unknown event type: %s", raw.Type)
+	}
+	data, err := decode(raw.Data)
+	if err != nil {
+		return Event{}, fmt.Errorf("invalid %s event: %w", raw.Type, err)
+	}
+	return Event{Type: raw.Type, Timestamp: raw.Timestamp, Data: data}, nil
+}
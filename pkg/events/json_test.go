@@ -0,0 +1,55 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIterationFailedData_JSON(t *testing.T) {
+	data := IterationFailedData{Current: 2, Total: 3, Error: errors.New("boom"), Reason: "max_turns"}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	want := `{"current":2,"total":3,"reason":"max_turns","error":"boom"}`
+	if string(encoded) != want {
+		t.Errorf("Marshal() = %s; want %s", encoded, want)
+	}
+
+	var decoded IterationFailedData
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error: %v", err)
+	}
+	if decoded.Current != 2 || decoded.Reason != "max_turns" || decoded.Error == nil || decoded.Error.Error() != "boom" {
+		t.Errorf("Unmarshal() = %+v; want the original data", decoded)
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	stamp := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	original := Event{
+		Type:      EventWinnerSelected,
+		Timestamp: stamp,
+		Data:      WinnerSelectedData{Winner: "impl-aaaaaa", Loser: "impl-bbbbbb", Judge: "cleaner"},
+	}
+
+	line, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal() error: %v", err)
+	}
+	decoded, err := DecodeEvent(line)
+	if err != nil {
+		t.Fatalf("DecodeEvent() error: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, original) {
+		t.Errorf("DecodeEvent() = %+v; want %+v", decoded, original)
+	}
+
+	if _, err := DecodeEvent([]byte(`{"type":"no_such_event","data":{}}`)); err == nil {
+		t.Error("Expected an error for an unknown event type")
+	}
+}
@@ -0,0 +1,18 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// NewRunID generates a sortable run identifier like "20250102-150405-a3f9c2"
+func NewRunID() string {
+	stamp := time.Now().Format("20060102-150405")
+	bytes := make([]byte, 3)
+	if _, err := rand.Read(bytes); err != nil {
+		return fmt.Sprintf("%s-%06d", stamp, time.Now().UnixNano()%1000000)
+	}
+	return fmt.Sprintf("%s-%s", stamp, hex.EncodeToString(bytes))
+}
@@ -0,0 +1,13 @@
+package events
+
+import "testing"
+
+func TestNewRunID(t *testing.T) {
+	id := NewRunID()
+	if len(id) != len("20060102-150405-a3f9c2") {
+		t.Errorf("Expected run ID like 20060102-150405-a3f9c2, got %q", id)
+	}
+	if other := NewRunID(); other == id {
+		t.Errorf("Expected unique run IDs, got %q twice", id)
+	}
+}
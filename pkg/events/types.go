@@ -11,7 +11,9 @@ const (
 	EventRunPromptStarted EventType = "run_prompt_started"
 
 	// Claude streaming events
+	EventClaudeSessionStarted   EventType = "claude_session_started"
 	EventClaudeAssistantMessage EventType = "claude_assistant_message"
+	EventClaudeThinking         EventType = "claude_thinking"
 	EventClaudeToolUse          EventType = "claude_tool_use"
 	EventClaudeToolResult       EventType = "claude_tool_result"
 	EventClaudeExecutionResult  EventType = "claude_execution_result"
@@ -20,7 +22,11 @@ const (
 	EventGitBranchCreated    EventType = "git_branch_created"
 	EventGitBranchCheckedOut EventType = "git_branch_checked_out"
 	EventGitBranchDeleted    EventType = "git_branch_deleted"
+	EventGitBranchRenamed    EventType = "git_branch_renamed"
 	EventGitCommitsSquashed  EventType = "git_commits_squashed"
+	EventGitStashed          EventType = "git_stashed"
+	EventGitStashRestored    EventType = "git_stash_restored"
+	EventGitWorktreeCreated  EventType = "git_worktree_created"
 
 	// Loop execution events
 	EventLoopStarted        EventType = "loop_started"
@@ -34,160 +40,300 @@ const (
 	EventEvolveStarted      EventType = "evolve_started"
 	EventRoundStarted       EventType = "round_started"
 	EventImprovementStarted EventType = "improvement_started"
+	EventCrossoverStarted   EventType = "crossover_started"
+	EventCandidateCommitted EventType = "candidate_committed"
+	EventChallengerFailed   EventType = "challenger_failed"
 	EventComparisonStarted  EventType = "comparison_started"
 	EventComparisonRetry    EventType = "comparison_retry"
 	EventWinnerSelected     EventType = "winner_selected"
+	EventEvolveFinished     EventType = "evolve_finished"
 	EventEvolveCompleted    EventType = "evolve_completed"
 	EventEvolveInterrupted  EventType = "evolve_interrupted"
+	EventEvolveCleanup      EventType = "evolve_cleanup"
 
 	EventSleepStarted EventType = "sleep_started"
+
+	// Statistics events
+	EventRunStats EventType = "run_stats"
 )
 
 // Event represents a single event in the system
 type Event struct {
-	Type      EventType
-	Timestamp time.Time
-	Data      interface{}
+	Type      EventType   `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
 }
 
 // RunPromptStartedData contains data for EventRunPromptStarted
 type RunPromptStartedData struct {
-	Prompt   string
-	BaseURL  string
-	Cwd      string
-	FileList string
+	Prompt   string `json:"prompt"`
+	BaseURL  string `json:"base_url"`
+	Cwd      string `json:"cwd"`
+	FileList string `json:"file_list"`
+}
+
+// SessionStartedData contains data for EventClaudeSessionStarted
+type SessionStartedData struct {
+	SessionID      string            `json:"session_id"`
+	Model          string            `json:"model"`
+	Cwd            string            `json:"cwd"`
+	Tools          []string          `json:"tools"`
+	MCPServers     []MCPServerStatus `json:"mcp_servers"`
+	PermissionMode string            `json:"permission_mode"`
+}
+
+// MCPServerStatus is the connection status of an MCP server
+type MCPServerStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
 }
 
 // AssistantMessageData contains data for EventAssistantMessage
 type AssistantMessageData struct {
-	Text string
+	Text            string `json:"text"`
+	ParentToolUseID string `json:"parent_tool_use_id"` // Task tool call that started the subagent sending this (empty = main agent)
+}
+
+// ThinkingData contains data for EventClaudeThinking
+type ThinkingData struct {
+	Text            string `json:"text"`
+	ParentToolUseID string `json:"parent_tool_use_id"` // Task tool call that started the subagent sending this (empty = main agent)
 }
 
 // ToolUseData contains data for EventToolUse
 type ToolUseData struct {
-	Name  string
-	Input map[string]interface{}
+	ID              string                 `json:"id"`
+	Name            string                 `json:"name"`
+	Input           map[string]interface{} `json:"input"`
+	ParentToolUseID string                 `json:"parent_tool_use_id"` // Task tool call that started the subagent sending this (empty = main agent)
 }
 
 // ToolResultData contains data for EventToolResult
 type ToolResultData struct {
-	Content string
+	ToolUseID       string        `json:"tool_use_id"`
+	Name            string        `json:"name"`        // Name of the tool call this answers (empty if the call was not seen)
+	Duration        time.Duration `json:"duration_ns"` // Time since the tool call was seen
+	IsError         bool          `json:"is_error"`
+	Content         string        `json:"content"`
+	ParentToolUseID string        `json:"parent_tool_use_id"` // Task tool call that started the subagent sending this (empty = main agent)
 }
 
 // ExecutionResultData contains data for EventExecutionResult
 type ExecutionResultData struct {
-	Duration time.Duration
+	Duration time.Duration `json:"duration_ns"`
+	CostUSD  float64       `json:"cost_usd"`
+	Tools    []ToolTiming  `json:"tools"` // Per-tool totals, in order of first use
+
+	InputTokens  int `json:"input_tokens"` // Including cache reads and writes
+	OutputTokens int `json:"output_tokens"`
+}
+
+// ToolTiming sums up the calls of one tool during a run
+type ToolTiming struct {
+	Name     string        `json:"name"`
+	Calls    int           `json:"calls"`
+	Errors   int           `json:"errors"`
+	Duration time.Duration `json:"duration_ns"` // Total time between the calls and their results
 }
 
 // LoopStartedData contains data for EventLoopStarted
 type LoopStartedData struct {
-	TotalIterations int
+	TotalIterations int `json:"total_iterations"`
 }
 
 // IterationStartedData contains data for EventIterationStarted
 type IterationStartedData struct {
-	Current int
-	Total   int
+	Current int    `json:"current"`
+	Total   int    `json:"total"`
+	Resume  string `json:"resume"` // Session continued by this iteration (empty = new session)
 }
 
 // IterationCompletedData contains data for EventIterationCompleted
 type IterationCompletedData struct {
-	Current  int
-	Total    int
-	Duration time.Duration
+	Current  int           `json:"current"`
+	Total    int           `json:"total"`
+	Duration time.Duration `json:"duration_ns"`
 }
 
 // IterationFailedData contains data for EventIterationFailed
 type IterationFailedData struct {
-	Current int
-	Total   int
-	Error   error
+	Current int    `json:"current"`
+	Total   int    `json:"total"`
+	Error   error  `json:"error"`
+	Reason  string `json:"reason"` // Error result kind reported by Claude, e.g. "max_turns" (empty for other failures)
 }
 
 // SleepStartedData contains data for EventSleepStarted
 type SleepStartedData struct {
-	Duration time.Duration
+	Duration time.Duration `json:"duration_ns"`
 }
 
 // LoopCompletedData contains data for EventLoopCompleted
 type LoopCompletedData struct {
-	TotalIterations      int
-	SuccessfulIterations int
-	FailedIterations     int
-	TotalDuration        time.Duration
+	TotalIterations      int           `json:"total_iterations"`
+	SuccessfulIterations int           `json:"successful_iterations"`
+	FailedIterations     int           `json:"failed_iterations"`
+	TotalDuration        time.Duration `json:"total_duration_ns"`
+}
+
+// RunStatsData contains data for EventRunStats
+type RunStatsData struct {
+	Iterations []ToolStats `json:"iterations"` // One per loop iteration or evolve round
+	Total      ToolStats   `json:"total"`
+}
+
+// ToolStats sums up the tool usage of an iteration or a whole run
+type ToolStats struct {
+	Label        string      `json:"label"`         // "Iteration 2", "Round 1" or "Total"
+	Tools        []ToolCount `json:"tools"`         // In order of first use
+	Errors       int         `json:"errors"`        // Tool results reported as errors
+	FilesTouched []string    `json:"files_touched"` // Files edited or written, sorted
+}
+
+// ToolCount counts the calls of one tool
+type ToolCount struct {
+	Name   string `json:"name"`
+	Calls  int    `json:"calls"`
+	Errors int    `json:"errors"`
 }
 
 // LoopInterruptedData contains data for EventLoopInterrupted
 type LoopInterruptedData struct {
-	CompletedIterations int
-	TotalIterations     int
+	CompletedIterations int `json:"completed_iterations"`
+	TotalIterations     int `json:"total_iterations"`
 }
 
 // EvolveStartedData contains data for EventEvolveStarted
 type EvolveStartedData struct {
-	TotalIterations int
+	TotalIterations int    `json:"total_iterations"`
+	RunID           string `json:"run_id"`
 }
 
 // BranchCreatedData contains data for EventBranchCreated
 type BranchCreatedData struct {
-	BranchName string
-	Base       string // Optional: base branch for CreateBranchFrom
+	BranchName string `json:"branch_name"`
+	Base       string `json:"base"` // Optional: base branch for CreateBranchFrom
 }
 
 // BranchCheckedOutData contains data for EventBranchCheckedOut
 type BranchCheckedOutData struct {
-	BranchName string
+	BranchName string `json:"branch_name"`
 }
 
 // BranchDeletedData contains data for EventBranchDeleted
 type BranchDeletedData struct {
-	BranchName string
+	BranchName string `json:"branch_name"`
+}
+
+// BranchRenamedData contains data for EventGitBranchRenamed
+type BranchRenamedData struct {
+	OldName string `json:"old_name"`
+	NewName string `json:"new_name"`
 }
 
 // CommitsSquashedData contains data for EventCommitsSquashed
 type CommitsSquashedData struct {
-	BranchName string
+	BranchName string `json:"branch_name"`
+}
+
+// StashData contains data for EventGitStashed and EventGitStashRestored
+type StashData struct {
+	Stash   string `json:"stash"`
+	Message string `json:"message"`
+}
+
+// WorktreeCreatedData contains data for EventGitWorktreeCreated
+type WorktreeCreatedData struct {
+	Path   string `json:"path"`
+	Branch string `json:"branch"` // Empty when the worktree has a detached HEAD
+	Base   string `json:"base"`
 }
 
 // RoundStartedData contains data for EventRoundStarted
 type RoundStartedData struct {
-	Round int
-	Total int
+	Round int `json:"round"`
+	Total int `json:"total"`
 }
 
 // ImprovementStartedData contains data for EventImprovementStarted
 type ImprovementStartedData struct {
-	BranchName string
+	BranchName string `json:"branch_name"`
+	Prompt     string `json:"prompt"`
+}
+
+// CrossoverStartedData contains data for EventCrossoverStarted
+type CrossoverStartedData struct {
+	BranchName string `json:"branch_name"`
+	Parent1    string `json:"parent1"`
+	Parent2    string `json:"parent2"`
+}
+
+// CandidateCommittedData contains data for EventCandidateCommitted
+type CandidateCommittedData struct {
+	BranchName string   `json:"branch_name"`
+	Round      int      `json:"round"` // 0 for the initial implementation
+	Parents    []string `json:"parents"`
+	Subject    string   `json:"subject"` // Subject line of the candidate commit
+	Diff       string   `json:"diff"`    // Changes against the branch the run started from
+	CostUSD    float64  `json:"cost_usd"`
+}
+
+// ChallengerFailedData contains data for EventChallengerFailed
+type ChallengerFailedData struct {
+	Round      int    `json:"round"`
+	BranchName string `json:"branch_name"`
+	Reason     string `json:"reason"` // Error result kind reported by Claude, e.g. "max_turns"
+	Error      string `json:"error"`
+	Action     string `json:"action"` // "deleted" or the name the branch was kept under
 }
 
 // ComparisonStartedData contains data for EventComparisonStarted
 type ComparisonStartedData struct {
-	Branch1 string
-	Branch2 string
+	Branch1 string `json:"branch1"`
+	Branch2 string `json:"branch2"`
 }
 
 // ComparisonRetryData contains data for EventComparisonRetry
 type ComparisonRetryData struct {
-	Attempt     int
-	MaxAttempts int
+	Attempt     int `json:"attempt"`
+	MaxAttempts int `json:"max_attempts"`
 }
 
 // WinnerSelectedData contains data for EventWinnerSelected
 type WinnerSelectedData struct {
-	Winner string
-	Loser  string
+	Winner string `json:"winner"`
+	Loser  string `json:"loser"`
+	Judge  string `json:"judge"` // The judge's response explaining the verdict
+}
+
+// EvolveFinishedData contains data for EventEvolveFinished
+type EvolveFinishedData struct {
+	Mode   string `json:"mode"`   // "merge", "squash", "rebase" or "pr-branch"
+	Winner string `json:"winner"` // Winning candidate branch
+	Branch string `json:"branch"` // Branch holding the result
+	Commit string `json:"commit"` // Resulting commit on Branch
 }
 
 // EvolveCompletedData contains data for EventEvolveCompleted
 type EvolveCompletedData struct {
-	FinalBranch   string
-	TotalRounds   int
-	TotalDuration time.Duration
+	FinalBranch   string        `json:"final_branch"`
+	TotalRounds   int           `json:"total_rounds"`
+	TotalDuration time.Duration `json:"total_duration_ns"`
 }
 
 // EvolveInterruptedData contains data for EventEvolveInterrupted
 type EvolveInterruptedData struct {
-	CompletedRounds int
-	TotalRounds     int
-	Winner          string
+	CompletedRounds int    `json:"completed_rounds"`
+	TotalRounds     int    `json:"total_rounds"`
+	Winner          string `json:"winner"`
+}
+
+// EvolveCleanupData contains data for EventEvolveCleanup
+type EvolveCleanupData struct {
+	Outcome          string `json:"outcome"`           // "completed", "failed" or "interrupted"
+	CheckedOut       string `json:"checked_out"`       // Branch checked out after cleanup
+	Winner           string `json:"winner"`            // Current winner when the run ended, if any
+	IncompleteBranch string `json:"incomplete_branch"` // Challenger that was still being built, if any
+	IncompleteAction string `json:"incomplete_action"` // "deleted" or the name it was kept under
+	Error            string `json:"error"`             // Cleanup problem the user should resolve by hand, if any
 }
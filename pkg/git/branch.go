@@ -13,14 +13,37 @@ import (
 
 // Client provides git operations with event emission
 type Client struct {
+	dir     string
 	emitter events.Emitter
 }
 
-// NewClient creates a new git client with the given emitter
+// NewClient creates a new git client operating in the current directory
 func NewClient(emitter events.Emitter) *Client {
-	return &Client{emitter: emitter}
+	return NewClientAt("", emitter)
 }
 
+// NewClientAt creates a new git client operating in the given directory
+func NewClientAt(dir string, emitter events.Emitter) *Client {
+	return &Client{dir: dir, emitter: emitter}
+}
+
+var _ Repo = (*Client)(nil)
+
+// Dir returns the working directory git commands run in (empty = current directory)
+func (c *Client) Dir() string {
+	return c.dir
+}
+
+// command builds a git command that runs in the client's working directory
+func (c *Client) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = c.dir
+	return cmd
+}
+
+// RunBranchPrefix starts the branch of an isolated loop run and of evolve's pr-branch finish
+const RunBranchPrefix = "agent-exec/"
+
 // RandomBranchName generates a random branch name like "impl-a3f9c2"
 func RandomBranchName() string {
 	bytes := make([]byte, 3)
@@ -33,7 +56,7 @@ func RandomBranchName() string {
 
 // CreateBranch creates a new branch from the current HEAD
 func (c *Client) CreateBranch(name string) error {
-	cmd := exec.Command("git", "checkout", "-b", name)
+	cmd := c.command("checkout", "-b", name)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to create branch %s: %s", name, string(output))
 	}
@@ -46,7 +69,7 @@ func (c *Client) CreateBranch(name string) error {
 
 // CreateBranchFrom creates a new branch from a specified base branch
 func (c *Client) CreateBranchFrom(name, base string) error {
-	cmd := exec.Command("git", "checkout", "-b", name, base)
+	cmd := c.command("checkout", "-b", name, base)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to create branch %s from %s: %s", name, base, string(output))
 	}
@@ -57,9 +80,9 @@ func (c *Client) CreateBranchFrom(name, base string) error {
 	return nil
 }
 
-// Checkout switches to the specified branch
+// Checkout switches to the specified branch, or detaches HEAD at a commit
 func (c *Client) Checkout(branch string) error {
-	cmd := exec.Command("git", "checkout", branch)
+	cmd := c.command("checkout", branch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to checkout %s: %s", branch, string(output))
 	}
@@ -72,7 +95,7 @@ func (c *Client) Checkout(branch string) error {
 // SquashCommits squashes all commits on current branch relative to base into one commit
 func (c *Client) SquashCommits(base, message string) error {
 	// Get the merge base
-	mergeBaseCmd := exec.Command("git", "merge-base", base, "HEAD")
+	mergeBaseCmd := c.command("merge-base", base, "HEAD")
 	mergeBaseOutput, err := mergeBaseCmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to find merge base: %w", err)
@@ -80,19 +103,19 @@ func (c *Client) SquashCommits(base, message string) error {
 	mergeBase := strings.TrimSpace(string(mergeBaseOutput))
 
 	// Soft reset to merge base (keeps changes staged)
-	resetCmd := exec.Command("git", "reset", "--soft", mergeBase)
+	resetCmd := c.command("reset", "--soft", mergeBase)
 	if output, err := resetCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to reset to base %s: %s", base, string(output))
 	}
 
 	// Stage all changes including untracked files
-	addCmd := exec.Command("git", "add", ".")
+	addCmd := c.command("add", ".")
 	if output, err := addCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to stage changes for squash: %s", string(output))
 	}
 
-	// Commit all staged changes
-	commitCmd := exec.Command("git", "commit", "-m", message)
+	// Commit all staged changes; a candidate that changed nothing still gets a commit
+	commitCmd := c.command("commit", "--allow-empty", "-m", message)
 	if output, err := commitCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to commit squashed changes: %s", string(output))
 	}
@@ -105,7 +128,7 @@ func (c *Client) SquashCommits(base, message string) error {
 
 // DeleteBranch deletes the specified branch
 func (c *Client) DeleteBranch(branch string) error {
-	cmd := exec.Command("git", "branch", "-D", branch)
+	cmd := c.command("branch", "-D", branch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to delete branch %s: %s", branch, string(output))
 	}
@@ -115,12 +138,81 @@ func (c *Client) DeleteBranch(branch string) error {
 	return nil
 }
 
+// Diff returns the changes introduced on branch since it diverged from base
+func (c *Client) Diff(base, branch string) (string, error) {
+	cmd := c.command("diff", base+"..."+branch)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s against %s: %w", branch, base, err)
+	}
+	return string(output), nil
+}
+
 // GetCurrentBranch returns the name of the current branch
 func (c *Client) GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd := c.command("rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
 	}
 	return strings.TrimSpace(string(output)), nil
 }
+
+// ListBranches returns local branch names mapped to the commits they point to
+func (c *Client) ListBranches() (map[string]string, error) {
+	cmd := c.command("for-each-ref", "refs/heads", "--format=%(refname:short) %(objectname)")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	branches := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 {
+			branches[fields[0]] = fields[1]
+		}
+	}
+	return branches, nil
+}
+
+// RenameBranch renames a local branch
+func (c *Client) RenameBranch(oldName, newName string) error {
+	cmd := c.command("branch", "-m", oldName, newName)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to rename branch %s to %s: %s", oldName, newName, string(output))
+	}
+	c.emitter.Emit(events.EventGitBranchRenamed, events.BranchRenamedData{
+		OldName: oldName,
+		NewName: newName,
+	})
+	return nil
+}
+
+// CommitAll stages every change in the working tree and commits it, even when there is nothing to commit
+func (c *Client) CommitAll(message string) error {
+	addCmd := c.command("add", "-A")
+	if output, err := addCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stage changes: %s", string(output))
+	}
+
+	commitCmd := c.command("commit", "--allow-empty", "-m", message)
+	if output, err := commitCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit changes: %s", string(output))
+	}
+	return nil
+}
+
+// DiscardChanges resets tracked files to HEAD and removes untracked files (ignored files are kept)
+func (c *Client) DiscardChanges() error {
+	resetCmd := c.command("reset", "--hard", "HEAD")
+	if output, err := resetCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to reset working tree: %s", string(output))
+	}
+
+	cleanCmd := c.command("clean", "-fd")
+	if output, err := cleanCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove untracked files: %s", string(output))
+	}
+	return nil
+}
@@ -0,0 +1,491 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeCommit is a commit in a FakeRepo
+type fakeCommit struct {
+	id      string
+	parent  string
+	message string
+}
+
+// fakeStash is a stash entry in a FakeRepo
+type fakeStash struct {
+	id    string
+	paths []string
+}
+
+// FakeRepo is an in-memory Repo for tests. It models branches, commits,
+// uncommitted changes, stashes, worktrees, run records and lineage notes without
+// running git.
+type FakeRepo struct {
+	mu        sync.Mutex
+	dir       string
+	commits   map[string]*fakeCommit
+	branches  map[string]string
+	current   string // Checked out branch, empty when HEAD is detached
+	detached  string // Commit HEAD points at while detached
+	dirty     []string
+	stashes   []fakeStash
+	worktrees []Worktree
+	runs      []RunRecord
+	notes     map[string]LineageRecord
+	noteOrder []string
+	conflicts map[string]bool
+	nextID    int
+}
+
+// NewFakeRepo creates a fake repository with one commit on the given branch
+func NewFakeRepo(branch string) *FakeRepo {
+	f := &FakeRepo{
+		dir:       "/fake/repo",
+		commits:   make(map[string]*fakeCommit),
+		branches:  make(map[string]string),
+		notes:     make(map[string]LineageRecord),
+		conflicts: make(map[string]bool),
+	}
+	f.branches[branch] = f.newCommit("", "initial commit")
+	f.current = branch
+	f.worktrees = []Worktree{{Path: f.dir, Branch: branch, Main: true}}
+	return f
+}
+
+var _ Repo = (*FakeRepo)(nil)
+
+// SetDirty simulates uncommitted changes to the given paths
+func (f *FakeRepo) SetDirty(paths ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dirty = append(f.dirty, paths...)
+}
+
+// SetConflict makes merging or rebasing the given branch fail with a conflict
+func (f *FakeRepo) SetConflict(branch string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.conflicts[branch] = true
+}
+
+// AddFakeWorktree registers a linked worktree holding branch
+func (f *FakeRepo) AddFakeWorktree(path, branch string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.worktrees = append(f.worktrees, Worktree{Path: path, Branch: branch, Head: f.branches[branch]})
+}
+
+func (f *FakeRepo) newCommit(parent, message string) string {
+	f.nextID++
+	id := fmt.Sprintf("%040d", f.nextID)
+	f.commits[id] = &fakeCommit{id: id, parent: parent, message: message}
+	return id
+}
+
+// head returns the commit HEAD points at
+func (f *FakeRepo) head() string {
+	if f.current == "" {
+		return f.detached
+	}
+	return f.branches[f.current]
+}
+
+// setHead moves the current branch, or the detached HEAD, to id
+func (f *FakeRepo) setHead(id string) {
+	if f.current == "" {
+		f.detached = id
+		return
+	}
+	f.branches[f.current] = id
+}
+
+// resolve returns the commit for a branch name, "HEAD" or commit id
+func (f *FakeRepo) resolve(ref string) (string, error) {
+	if ref == "HEAD" {
+		return f.head(), nil
+	}
+	if id, ok := f.branches[ref]; ok {
+		return id, nil
+	}
+	if _, ok := f.commits[ref]; ok {
+		return ref, nil
+	}
+	return "", fmt.Errorf("failed to resolve %s: unknown revision", ref)
+}
+
+func (f *FakeRepo) Dir() string {
+	return f.dir
+}
+
+func (f *FakeRepo) GetCurrentBranch() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.current == "" {
+		return "HEAD", nil
+	}
+	return f.current, nil
+}
+
+func (f *FakeRepo) CreateBranch(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.createBranchFrom(name, "HEAD")
+}
+
+func (f *FakeRepo) CreateBranchFrom(name, base string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.createBranchFrom(name, base)
+}
+
+func (f *FakeRepo) createBranchFrom(name, base string) error {
+	if _, exists := f.branches[name]; exists {
+		return fmt.Errorf("failed to create branch %s: already exists", name)
+	}
+	id, err := f.resolve(base)
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s from %s: %w", name, base, err)
+	}
+	f.branches[name] = id
+	f.current = name
+	return nil
+}
+
+// Checkout switches to a branch, or detaches HEAD at a commit id
+func (f *FakeRepo) Checkout(branch string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.branches[branch]; ok {
+		f.current = branch
+		return nil
+	}
+	if _, ok := f.commits[branch]; ok {
+		f.current = ""
+		f.detached = branch
+		return nil
+	}
+	return fmt.Errorf("failed to checkout %s: no such branch", branch)
+}
+
+func (f *FakeRepo) DeleteBranch(branch string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.branches[branch]; !ok {
+		return fmt.Errorf("failed to delete branch %s: no such branch", branch)
+	}
+	if branch == f.current {
+		return fmt.Errorf("failed to delete branch %s: checked out", branch)
+	}
+	delete(f.branches, branch)
+	return nil
+}
+
+func (f *FakeRepo) RenameBranch(oldName, newName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, ok := f.branches[oldName]
+	if !ok {
+		return fmt.Errorf("failed to rename branch %s: no such branch", oldName)
+	}
+	if _, exists := f.branches[newName]; exists {
+		return fmt.Errorf("failed to rename branch %s to %s: already exists", oldName, newName)
+	}
+	delete(f.branches, oldName)
+	f.branches[newName] = id
+	if f.current == oldName {
+		f.current = newName
+	}
+	return nil
+}
+
+func (f *FakeRepo) ListBranches() (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	branches := make(map[string]string, len(f.branches))
+	for name, id := range f.branches {
+		branches[name] = id
+	}
+	return branches, nil
+}
+
+func (f *FakeRepo) HeadCommit() (string, error) {
+	return f.ResolveCommit("HEAD")
+}
+
+func (f *FakeRepo) ResolveCommit(ref string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.resolve(ref)
+}
+
+func (f *FakeRepo) CommitMessage(ref string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, err := f.resolve(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(f.commits[id].message), nil
+}
+
+// SquashCommits replaces the current branch with a single commit on top of base
+func (f *FakeRepo) SquashCommits(base, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	baseID, err := f.resolve(base)
+	if err != nil {
+		return fmt.Errorf("failed to find merge base: %w", err)
+	}
+	f.setHead(f.newCommit(baseID, message))
+	f.dirty = nil
+	return nil
+}
+
+func (f *FakeRepo) CommitAll(message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setHead(f.newCommit(f.head(), message))
+	f.dirty = nil
+	return nil
+}
+
+func (f *FakeRepo) DiscardChanges() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dirty = nil
+	return nil
+}
+
+// Diff lists the messages of the commits on branch that are not on base
+func (f *FakeRepo) Diff(base, branch string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	baseID, err := f.resolve(base)
+	if err != nil {
+		return "", err
+	}
+	id, err := f.resolve(branch)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for id != "" && id != baseID {
+		commit := f.commits[id]
+		fmt.Fprintf(&b, "commit %s\n%s\n", id, commit.message)
+		id = commit.parent
+	}
+	return b.String(), nil
+}
+
+func (f *FakeRepo) Merge(branch, message string) error {
+	return f.integrate("merge", branch, message)
+}
+
+func (f *FakeRepo) SquashMerge(branch, message string) error {
+	return f.integrate("squash merge", branch, message)
+}
+
+func (f *FakeRepo) integrate(operation, branch, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.resolve(branch); err != nil {
+		return err
+	}
+	if f.conflicts[branch] {
+		return &ConflictError{Operation: operation, Branch: branch, Files: []string{"conflict.txt"}}
+	}
+	f.setHead(f.newCommit(f.head(), message))
+	return nil
+}
+
+func (f *FakeRepo) RebaseOnto(branch, base string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, err := f.resolve(branch)
+	if err != nil {
+		return err
+	}
+	baseID, err := f.resolve(base)
+	if err != nil {
+		return err
+	}
+	if f.conflicts[branch] {
+		return &ConflictError{Operation: "rebase", Branch: branch, Files: []string{"conflict.txt"}}
+	}
+	if !f.isAncestor(baseID, id) {
+		f.branches[branch] = f.newCommit(baseID, f.commits[id].message)
+	}
+	f.current = branch
+	return nil
+}
+
+func (f *FakeRepo) FastForward(branch string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, err := f.resolve(branch)
+	if err != nil {
+		return err
+	}
+	if !f.isAncestor(f.head(), id) {
+		return fmt.Errorf("failed to fast-forward to %s: not possible", branch)
+	}
+	f.setHead(id)
+	return nil
+}
+
+// isAncestor reports whether ancestor is reachable from id through parents
+func (f *FakeRepo) isAncestor(ancestor, id string) bool {
+	for id != "" {
+		if id == ancestor {
+			return true
+		}
+		id = f.commits[id].parent
+	}
+	return false
+}
+
+func (f *FakeRepo) Preflight(allowDirty bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.dirty) > 0 && !allowDirty {
+		return fmt.Errorf("working tree has uncommitted changes (%s); commit them or use --stash", strings.Join(f.dirty, ", "))
+	}
+	return nil
+}
+
+func (f *FakeRepo) DirtyPaths() ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.dirty...), nil
+}
+
+func (f *FakeRepo) StashPush(message string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.dirty) == 0 {
+		return "", nil
+	}
+	id := f.newCommit(f.head(), message)
+	f.stashes = append(f.stashes, fakeStash{id: id, paths: f.dirty})
+	f.dirty = nil
+	return id, nil
+}
+
+func (f *FakeRepo) StashPop(stash string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, entry := range f.stashes {
+		if entry.id == stash {
+			f.dirty = append(f.dirty, entry.paths...)
+			f.stashes = append(f.stashes[:i], f.stashes[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("stash %s not found; your changes may already have been restored", stash)
+}
+
+func (f *FakeRepo) ListWorktrees() ([]Worktree, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Worktree(nil), f.worktrees...), nil
+}
+
+// AddWorktree records a linked worktree and checks out base in it. The returned
+// Repo reports the worktree's path from Dir but shares the fake's single HEAD.
+func (f *FakeRepo) AddWorktree(name, base, branch string) (Repo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, err := f.resolve(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worktree %s: %w", name, err)
+	}
+	path := f.dir + "/" + WorktreesDir + "/" + name
+
+	if branch != "" {
+		if _, exists := f.branches[branch]; exists {
+			return nil, fmt.Errorf("failed to create worktree %s: branch %s already exists", path, branch)
+		}
+		f.branches[branch] = id
+		f.current = branch
+	} else {
+		f.current = ""
+		f.detached = id
+	}
+	f.worktrees = append(f.worktrees, Worktree{Path: path, Head: id, Branch: branch})
+	f.runs = append(f.runs, RunRecord{RunID: name, Branch: branch, Worktree: path, CreatedAt: time.Now()})
+	return &fakeWorktree{FakeRepo: f, path: path}, nil
+}
+
+func (f *FakeRepo) ReadRuns() ([]RunRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]RunRecord(nil), f.runs...), nil
+}
+
+func (f *FakeRepo) DeleteRun(runID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, run := range f.runs {
+		if run.RunID == runID {
+			f.runs = append(f.runs[:i], f.runs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// fakeWorktree is a FakeRepo seen from one of its linked worktrees
+type fakeWorktree struct {
+	*FakeRepo
+	path string
+}
+
+func (w *fakeWorktree) Dir() string {
+	return w.path
+}
+
+func (f *FakeRepo) RemoveWorktree(path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, wt := range f.worktrees {
+		if wt.Path == path && !wt.Main {
+			f.worktrees = append(f.worktrees[:i], f.worktrees[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("failed to remove worktree " + path + ": not a linked worktree")
+}
+
+func (f *FakeRepo) WriteLineage(record *LineageRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.commits[record.Commit]; !ok {
+		return fmt.Errorf("failed to write lineage note for %s: unknown commit %s", record.Branch, record.Commit)
+	}
+	if _, exists := f.notes[record.Commit]; !exists {
+		f.noteOrder = append(f.noteOrder, record.Commit)
+	}
+	copied := *record
+	copied.Parents = append([]string(nil), record.Parents...)
+	f.notes[record.Commit] = copied
+	return nil
+}
+
+func (f *FakeRepo) ReadLineage() ([]LineageRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	records := make([]LineageRecord, 0, len(f.noteOrder))
+	for _, commit := range f.noteOrder {
+		records = append(records, f.notes[commit])
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+	return records, nil
+}
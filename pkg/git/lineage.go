@@ -0,0 +1,100 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NotesRef is the git notes ref holding agent-exec lineage metadata
+const NotesRef = "refs/notes/agent-exec"
+
+// Verdicts recorded for a candidate after comparison
+const (
+	VerdictPending    = "pending"
+	VerdictWon        = "won"
+	VerdictLost       = "lost"
+	VerdictIncomplete = "incomplete"
+)
+
+// LineageRecord describes how an evolve candidate was produced and how it fared
+type LineageRecord struct {
+	RunID     string    `json:"run_id"`
+	Branch    string    `json:"branch"`
+	Commit    string    `json:"commit"`
+	Parents   []string  `json:"parents"` // Parent candidate branches, or the original branch for the initial implementation
+	Round     int       `json:"round"`   // 0 for the initial implementation
+	Prompt    string    `json:"prompt"`
+	Verdict   string    `json:"verdict"`
+	Judge     string    `json:"judge,omitempty"` // Latest comparison response from the judge
+	CostUSD   float64   `json:"cost_usd"`
+	Fitness   int       `json:"fitness"`         // Number of comparisons survived
+	Final     bool      `json:"final,omitempty"` // Winner of the whole run
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HeadCommit returns the commit hash HEAD points to
+func (c *Client) HeadCommit() (string, error) {
+	return c.ResolveCommit("HEAD")
+}
+
+// ResolveCommit returns the commit hash a ref points to
+func (c *Client) ResolveCommit(ref string) (string, error) {
+	cmd := c.command("rev-parse", "--verify", ref+"^{commit}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// WriteLineage attaches the record as a git note to its commit, replacing any previous note
+func (c *Client) WriteLineage(record *LineageRecord) error {
+	content, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode lineage for %s: %w", record.Branch, err)
+	}
+
+	cmd := c.command("notes", "--ref", NotesRef, "add", "-f", "-m", string(content), record.Commit)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to write lineage note for %s: %s", record.Branch, string(output))
+	}
+	return nil
+}
+
+// ReadLineage returns all lineage records stored in the notes ref
+func (c *Client) ReadLineage() ([]LineageRecord, error) {
+	listCmd := c.command("notes", "--ref", NotesRef, "list")
+	output, err := listCmd.Output()
+	if err != nil {
+		// The notes ref does not exist until the first note is written
+		if c.command("rev-parse", "--verify", "--quiet", NotesRef).Run() != nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list lineage notes: %w", err)
+	}
+
+	var records []LineageRecord
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		showCmd := c.command("cat-file", "-p", fields[0])
+		content, err := showCmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read lineage note for %s: %w", fields[1], err)
+		}
+
+		var record LineageRecord
+		if err := json.Unmarshal(content, &record); err != nil {
+			// Not an agent-exec record; skip it
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
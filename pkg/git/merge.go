@@ -0,0 +1,93 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictError reports conflicting files of an aborted merge or rebase
+type ConflictError struct {
+	Operation string
+	Branch    string
+	Files     []string
+}
+
+func (e *ConflictError) Error() string {
+	if len(e.Files) == 0 {
+		return fmt.Sprintf("%s of %s failed and was aborted", e.Operation, e.Branch)
+	}
+	return fmt.Sprintf("%s of %s conflicts in %s; aborted, branch %s is unchanged",
+		e.Operation, e.Branch, strings.Join(e.Files, ", "), e.Branch)
+}
+
+// Merge merges branch into the current branch with a merge commit
+func (c *Client) Merge(branch, message string) error {
+	cmd := c.command("merge", "--no-ff", "-m", message, branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		files := c.conflictedFiles()
+		if abortErr := c.command("merge", "--abort").Run(); abortErr != nil && len(files) == 0 {
+			return fmt.Errorf("failed to merge %s: %s", branch, string(output))
+		}
+		return &ConflictError{Operation: "merge", Branch: branch, Files: files}
+	}
+	return nil
+}
+
+// SquashMerge applies all changes of branch to the current branch as a single commit
+func (c *Client) SquashMerge(branch, message string) error {
+	mergeCmd := c.command("merge", "--squash", branch)
+	if output, err := mergeCmd.CombinedOutput(); err != nil {
+		files := c.conflictedFiles()
+		if resetErr := c.command("reset", "--hard", "HEAD").Run(); resetErr != nil && len(files) == 0 {
+			return fmt.Errorf("failed to squash merge %s: %s", branch, string(output))
+		}
+		return &ConflictError{Operation: "squash merge", Branch: branch, Files: files}
+	}
+
+	commitCmd := c.command("commit", "-m", message)
+	if output, err := commitCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit squash merge of %s: %s", branch, string(output))
+	}
+	return nil
+}
+
+// RebaseOnto replays branch onto base, leaving branch checked out
+func (c *Client) RebaseOnto(branch, base string) error {
+	cmd := c.command("rebase", base, branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		files := c.conflictedFiles()
+		if abortErr := c.command("rebase", "--abort").Run(); abortErr != nil && len(files) == 0 {
+			return fmt.Errorf("failed to rebase %s onto %s: %s", branch, base, string(output))
+		}
+		return &ConflictError{Operation: "rebase", Branch: branch, Files: files}
+	}
+	return nil
+}
+
+// FastForward advances the current branch to branch, failing if that is not a fast-forward
+func (c *Client) FastForward(branch string) error {
+	cmd := c.command("merge", "--ff-only", branch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fast-forward to %s: %s", branch, string(output))
+	}
+	return nil
+}
+
+// CommitMessage returns the full message of the commit ref points to
+func (c *Client) CommitMessage(ref string) (string, error) {
+	cmd := c.command("log", "-1", "--format=%B", ref)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message of %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// conflictedFiles lists files with unresolved conflicts
+func (c *Client) conflictedFiles() []string {
+	output, err := c.command("diff", "--name-only", "--diff-filter=U").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(output))
+}
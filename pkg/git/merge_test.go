@@ -0,0 +1,39 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestConflictError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *ConflictError
+		expected string
+	}{
+		{
+			name:     "with conflicting files",
+			err:      &ConflictError{Operation: "merge", Branch: "impl-abc123", Files: []string{"main.go", "go.mod"}},
+			expected: "merge of impl-abc123 conflicts in main.go, go.mod; aborted, branch impl-abc123 is unchanged",
+		},
+		{
+			name:     "without files",
+			err:      &ConflictError{Operation: "rebase", Branch: "impl-abc123"},
+			expected: "rebase of impl-abc123 failed and was aborted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.expected {
+				t.Errorf("Error() = %q; want %q", got, tt.expected)
+			}
+
+			var conflict *ConflictError
+			if !errors.As(fmt.Errorf("finish failed: %w", tt.err), &conflict) {
+				t.Error("Expected wrapped error to unwrap to *ConflictError")
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// maxDirtyPathsShown limits how many uncommitted paths are listed in the dirty tree error
+const maxDirtyPathsShown = 5
+
+// inProgressMarkers maps files in the git directory to the operation they indicate
+var inProgressMarkers = []struct {
+	path      string
+	operation string
+}{
+	{"MERGE_HEAD", "merge"},
+	{"rebase-merge", "rebase"},
+	{"rebase-apply", "rebase"},
+	{"CHERRY_PICK_HEAD", "cherry-pick"},
+	{"REVERT_HEAD", "revert"},
+	{"BISECT_LOG", "bisect"},
+}
+
+// Preflight verifies the repository is in a state that is safe to mutate: inside a
+// work tree, with at least one commit, on a branch, with no merge/rebase/cherry-pick
+// in progress and, unless allowDirty is set, without uncommitted changes.
+func (c *Client) Preflight(allowDirty bool) error {
+	insideCmd := c.command("rev-parse", "--is-inside-work-tree")
+	if output, err := insideCmd.Output(); err != nil || strings.TrimSpace(string(output)) != "true" {
+		return errors.New("not inside a git working tree; run agent-exec from a git repository")
+	}
+
+	if err := c.command("rev-parse", "--verify", "--quiet", "HEAD").Run(); err != nil {
+		return errors.New("repository has no commits yet; create an initial commit first")
+	}
+
+	if err := c.command("symbolic-ref", "--quiet", "HEAD").Run(); err != nil {
+		return errors.New("HEAD is detached; check out a branch first")
+	}
+
+	// Absolute, since the git directory is relative to the client's directory, not the process's
+	gitDirCmd := c.command("rev-parse", "--absolute-git-dir")
+	gitDirOutput, err := gitDirCmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	gitDir := strings.TrimSpace(string(gitDirOutput))
+	for _, marker := range inProgressMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, marker.path)); err == nil {
+			return fmt.Errorf("a %s is in progress; finish or abort it first", marker.operation)
+		}
+	}
+
+	if allowDirty {
+		return nil
+	}
+
+	dirty, err := c.DirtyPaths()
+	if err != nil {
+		return err
+	}
+	if len(dirty) > 0 {
+		shown := dirty
+		if len(shown) > maxDirtyPathsShown {
+			shown = shown[:maxDirtyPathsShown]
+		}
+		msg := fmt.Sprintf("working tree has uncommitted changes (%s", strings.Join(shown, ", "))
+		if len(dirty) > len(shown) {
+			msg += fmt.Sprintf(" and %d more", len(dirty)-len(shown))
+		}
+		return errors.New(msg + "); commit them or use --stash")
+	}
+
+	return nil
+}
+
+// DirtyPaths returns paths with uncommitted changes, including untracked files
+func (c *Client) DirtyPaths() ([]string, error) {
+	cmd := c.command("status", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working tree status: %w", err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) > 3 {
+			paths = append(paths, line[3:])
+		}
+	}
+	return paths, nil
+}
+
+// StashPush stashes all uncommitted changes including untracked files and
+// returns the stash commit, or an empty string when there was nothing to stash
+func (c *Client) StashPush(message string) (string, error) {
+	dirty, err := c.DirtyPaths()
+	if err != nil {
+		return "", err
+	}
+	if len(dirty) == 0 {
+		return "", nil
+	}
+
+	pushCmd := c.command("stash", "push", "--include-untracked", "-m", message)
+	if output, err := pushCmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to stash changes: %s", string(output))
+	}
+
+	revCmd := c.command("rev-parse", "stash@{0}")
+	output, err := revCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve stash: %w", err)
+	}
+	stash := strings.TrimSpace(string(output))
+
+	c.emitter.Emit(events.EventGitStashed, events.StashData{
+		Stash:   stash,
+		Message: message,
+	})
+	return stash, nil
+}
+
+// StashPop restores the stash created by StashPush and drops it from the stash list
+func (c *Client) StashPop(stash string) error {
+	listCmd := c.command("stash", "list", "--format=%gd %H")
+	output, err := listCmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to list stashes: %w", err)
+	}
+
+	ref := ""
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == stash {
+			ref = fields[0]
+			break
+		}
+	}
+	if ref == "" {
+		return fmt.Errorf("stash %s not found; your changes may already have been restored", stash)
+	}
+
+	popCmd := c.command("stash", "pop", "--index", ref)
+	if output, err := popCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore stashed changes from %s (run 'git stash pop %s' manually): %s", ref, ref, string(output))
+	}
+
+	c.emitter.Emit(events.EventGitStashRestored, events.StashData{
+		Stash: stash,
+	})
+	return nil
+}
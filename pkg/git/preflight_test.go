@@ -0,0 +1,77 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// initRepo creates a git repository with one commit on main
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "--quiet", "--initial-branch", "main")
+	runGit(t, dir, "config", "user.name", "agent-exec test")
+	runGit(t, dir, "config", "user.email", "test@agent-exec.invalid")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "README.md")
+	runGit(t, dir, "commit", "--quiet", "-m", "initial commit")
+	return dir
+}
+
+// runGit runs git in dir
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s failed: %s", strings.Join(args, " "), output)
+	}
+}
+
+func TestPreflight_InProgressFromOtherDirectory(t *testing.T) {
+	tests := []struct {
+		name    string
+		marker  string
+		wantErr string
+	}{
+		{name: "clean"},
+		{name: "merge", marker: "MERGE_HEAD", wantErr: "a merge is in progress"},
+		{name: "rebase", marker: "rebase-merge", wantErr: "a rebase is in progress"},
+		{name: "cherry-pick", marker: "CHERRY_PICK_HEAD", wantErr: "a cherry-pick is in progress"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := initRepo(t)
+			if tt.marker != "" {
+				if err := os.WriteFile(filepath.Join(dir, ".git", tt.marker), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// The process runs elsewhere; the client must still look in its own repository
+			t.Chdir(t.TempDir())
+
+			err := NewClientAt(dir, events.NewNullEmitter()).Preflight(false)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Preflight() = %v; want no error", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Preflight() = %v; want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
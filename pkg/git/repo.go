@@ -0,0 +1,49 @@
+package git
+
+// Repo is the set of git operations agent-exec performs on a repository.
+// Client implements it by running git; FakeRepo keeps state in memory for tests.
+type Repo interface {
+	// Dir returns the working directory operations run in (empty = current directory)
+	Dir() string
+
+	// Branches
+	GetCurrentBranch() (string, error)
+	CreateBranch(name string) error
+	CreateBranchFrom(name, base string) error
+	Checkout(branch string) error
+	DeleteBranch(branch string) error
+	RenameBranch(oldName, newName string) error
+	ListBranches() (map[string]string, error)
+
+	// Commits
+	HeadCommit() (string, error)
+	ResolveCommit(ref string) (string, error)
+	CommitMessage(ref string) (string, error)
+	SquashCommits(base, message string) error
+	CommitAll(message string) error
+	DiscardChanges() error
+	Diff(base, branch string) (string, error)
+
+	// Integration
+	Merge(branch, message string) error
+	SquashMerge(branch, message string) error
+	RebaseOnto(branch, base string) error
+	FastForward(branch string) error
+
+	// Working tree safety
+	Preflight(allowDirty bool) error
+	DirtyPaths() ([]string, error)
+	StashPush(message string) (string, error)
+	StashPop(stash string) error
+
+	// Worktrees
+	ListWorktrees() ([]Worktree, error)
+	AddWorktree(name, base, branch string) (Repo, error)
+	RemoveWorktree(path string) error
+	ReadRuns() ([]RunRecord, error)
+	DeleteRun(runID string) error
+
+	// Lineage notes
+	WriteLineage(record *LineageRecord) error
+	ReadLineage() ([]LineageRecord, error)
+}
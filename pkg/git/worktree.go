@@ -0,0 +1,248 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// StateDir is the directory at the repository root where agent-exec keeps its files
+const StateDir = ".agent-exec"
+
+// WorktreesDir holds the worktrees of isolated runs, relative to the repository root
+var WorktreesDir = filepath.Join(StateDir, "worktrees")
+
+// RunsDir holds the event logs of runs, relative to the repository root
+var RunsDir = filepath.Join(StateDir, "runs")
+
+// RunFile records the worktree and branch of an isolated run inside its run directory
+const RunFile = "run.json"
+
+// RunRecord is written when agent-exec creates a worktree, so "agent-exec
+// clean" can find what an isolated run left behind without guessing from names
+type RunRecord struct {
+	RunID     string    `json:"run_id"`
+	Branch    string    `json:"branch,omitempty"` // Branch created with the worktree, empty when detached
+	Worktree  string    `json:"worktree"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Worktree describes an entry of "git worktree list"
+type Worktree struct {
+	Path   string
+	Head   string
+	Branch string // Short branch name, empty when detached
+	Main   bool   // The repository's main working tree
+}
+
+// ListWorktrees returns all working trees attached to the repository
+func (c *Client) ListWorktrees() ([]Worktree, error) {
+	cmd := c.command("worktree", "list", "--porcelain")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	return parseWorktreeList(string(output)), nil
+}
+
+// AddWorktree creates a linked working tree named name under .agent-exec/worktrees,
+// checked out at base on a new branch, or with a detached HEAD when branch is empty.
+// The worktree is recorded in .agent-exec/runs/<name> first, so it is never left
+// behind unrecorded. It returns a Client operating in the new worktree.
+func (c *Client) AddWorktree(name, base, branch string) (Repo, error) {
+	path, err := c.StatePath(WorktreesDir, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.writeRun(&RunRecord{RunID: name, Branch: branch, Worktree: path, CreatedAt: time.Now()}); err != nil {
+		return nil, err
+	}
+
+	args := []string{"worktree", "add"}
+	if branch != "" {
+		args = append(args, "-b", branch)
+	} else {
+		args = append(args, "--detach")
+	}
+	args = append(args, path, base)
+	if output, err := c.command(args...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to create worktree %s: %s", path, string(output))
+	}
+
+	c.emitter.Emit(events.EventGitWorktreeCreated, events.WorktreeCreatedData{
+		Path:   path,
+		Branch: branch,
+		Base:   base,
+	})
+	return NewClientAt(path, c.emitter), nil
+}
+
+// writeRun saves the record in its run directory
+func (c *Client) writeRun(record *RunRecord) error {
+	dir, err := c.StatePath(RunsDir, record.RunID)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run %s: %w", record.RunID, err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create run directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, RunFile), content, 0o644); err != nil {
+		return fmt.Errorf("failed to record run %s: %w", record.RunID, err)
+	}
+	return nil
+}
+
+// ReadRuns returns the recorded runs that created worktrees, oldest first
+func (c *Client) ReadRuns() ([]RunRecord, error) {
+	runsDir, err := c.StatePath(RunsDir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(runsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	var runs []RunRecord
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(runsDir, entry.Name(), RunFile))
+		if os.IsNotExist(err) {
+			// A run log without a worktree
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read run %s: %w", entry.Name(), err)
+		}
+		var record RunRecord
+		if err := json.Unmarshal(content, &record); err != nil {
+			continue
+		}
+		runs = append(runs, record)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.Before(runs[j].CreatedAt)
+	})
+	return runs, nil
+}
+
+// DeleteRun forgets a recorded run once its worktree and branch are gone. The
+// run directory itself is only removed when no run log remains in it.
+func (c *Client) DeleteRun(runID string) error {
+	dir, err := c.StatePath(RunsDir, runID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, RunFile)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete run record %s: %w", runID, err)
+	}
+	_ = os.Remove(dir)
+	return nil
+}
+
+// StatePath joins elem to the repository root, for paths inside StateDir. It
+// makes sure git ignores the state directory.
+func (c *Client) StatePath(elem ...string) (string, error) {
+	topCmd := c.command("rev-parse", "--show-toplevel")
+	topOutput, err := topCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate repository root: %w", err)
+	}
+
+	if err := c.excludeStateDir(); err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{strings.TrimSpace(string(topOutput))}, elem...)...), nil
+}
+
+// excludeStateDir adds the agent-exec state directory to .git/info/exclude so
+// worktrees inside it never show up as untracked files
+func (c *Client) excludeStateDir() error {
+	commonDirCmd := c.command("rev-parse", "--path-format=absolute", "--git-common-dir")
+	commonDirOutput, err := commonDirCmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	excludePath := filepath.Join(strings.TrimSpace(string(commonDirOutput)), "info", "exclude")
+	pattern := "/" + StateDir + "/"
+
+	existing, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", excludePath, err)
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(excludePath), err)
+	}
+	file, err := os.OpenFile(excludePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", excludePath, err)
+	}
+	defer file.Close()
+
+	entry := pattern + "\n"
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		entry = "\n" + entry
+	}
+	if _, err := file.WriteString(entry); err != nil {
+		return fmt.Errorf("failed to update %s: %w", excludePath, err)
+	}
+	return nil
+}
+
+// IsRunWorktree reports whether path is a worktree agent-exec created under WorktreesDir
+func IsRunWorktree(path string) bool {
+	return strings.Contains(filepath.ToSlash(path), "/"+filepath.ToSlash(WorktreesDir)+"/")
+}
+
+// RemoveWorktree deletes a linked working tree, discarding any changes in it
+func (c *Client) RemoveWorktree(path string) error {
+	cmd := c.command("worktree", "remove", "--force", path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %s", path, string(output))
+	}
+	return nil
+}
+
+// parseWorktreeList parses the porcelain output of "git worktree list"
+func parseWorktreeList(output string) []Worktree {
+	var worktrees []Worktree
+	var current *Worktree
+
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			worktrees = append(worktrees, Worktree{
+				Path: strings.TrimPrefix(line, "worktree "),
+				Main: len(worktrees) == 0,
+			})
+			current = &worktrees[len(worktrees)-1]
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "HEAD "):
+			current.Head = strings.TrimPrefix(line, "HEAD ")
+		case strings.HasPrefix(line, "branch "):
+			current.Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		}
+	}
+
+	return worktrees
+}
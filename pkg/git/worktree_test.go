@@ -0,0 +1,65 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	output := `worktree /home/user/project
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /home/user/project/.agent-exec/worktrees/run-1
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/impl-abc123
+
+worktree /tmp/detached
+HEAD 3333333333333333333333333333333333333333
+detached
+`
+
+	want := []Worktree{
+		{Path: "/home/user/project", Head: "1111111111111111111111111111111111111111", Branch: "main", Main: true},
+		{Path: "/home/user/project/.agent-exec/worktrees/run-1", Head: "2222222222222222222222222222222222222222", Branch: "impl-abc123"},
+		{Path: "/tmp/detached", Head: "3333333333333333333333333333333333333333"},
+	}
+
+	got := parseWorktreeList(output)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWorktreeList() = %+v; want %+v", got, want)
+	}
+}
+
+func TestAddWorktree_RecordsRun(t *testing.T) {
+	dir := initRepo(t)
+	client := NewClientAt(dir, events.NewNullEmitter())
+
+	worktree, err := client.AddWorktree("run-1", "main", RunBranchPrefix+"run-1")
+	if err != nil {
+		t.Fatalf("AddWorktree failed: %v", err)
+	}
+	// A run log without a worktree is not a recorded run
+	if err := os.MkdirAll(filepath.Join(dir, RunsDir, "run-2"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := client.ReadRuns()
+	if err != nil {
+		t.Fatalf("ReadRuns failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].RunID != "run-1" || runs[0].Branch != "agent-exec/run-1" || runs[0].Worktree != worktree.Dir() {
+		t.Fatalf("got runs %+v; want run-1 with its branch and worktree %s", runs, worktree.Dir())
+	}
+
+	if err := client.DeleteRun("run-1"); err != nil {
+		t.Fatalf("DeleteRun failed: %v", err)
+	}
+	if runs, _ := client.ReadRuns(); len(runs) != 0 {
+		t.Errorf("got runs %+v after DeleteRun; want none", runs)
+	}
+}
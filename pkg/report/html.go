@@ -0,0 +1,124 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/display"
+)
+
+// diffLine is a line of a diff with its CSS class
+type diffLine struct {
+	Class string
+	Text  string
+}
+
+var textFormatter = display.NewTextFormatter()
+
+var templateFuncs = template.FuncMap{
+	"duration": func(d time.Duration) string { return textFormatter.FormatDuration(d) },
+	"cost":     func(usd float64) string { return fmt.Sprintf("$%.4f", usd) },
+	"time":     func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	"add":      func(a, b int) int { return a + b },
+	"diffLines": func(diff string) []diffLine {
+		var lines []diffLine
+		for _, text := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+			class := ""
+			switch {
+			case strings.HasPrefix(text, "+++"), strings.HasPrefix(text, "---"), strings.HasPrefix(text, "diff "):
+				class = "file"
+			case strings.HasPrefix(text, "@@"):
+				class = "hunk"
+			case strings.HasPrefix(text, "+"):
+				class = "add"
+			case strings.HasPrefix(text, "-"):
+				class = "del"
+			}
+			lines = append(lines, diffLine{Class: class, Text: text})
+		}
+		return lines
+	},
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>agent-exec {{.Mode}} {{.RunID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1100px; color: #222; }
+h1 { font-size: 1.6em; } h2 { border-bottom: 1px solid #ddd; padding-bottom: .2em; margin-top: 2em; }
+table.summary td { padding: .2em 1em .2em 0; vertical-align: top; }
+table.stats { border-collapse: collapse; } table.stats td, table.stats th { border: 1px solid #ddd; padding: .2em .6em; text-align: left; }
+pre { background: #f6f8fa; padding: .6em; overflow-x: auto; font-size: .85em; white-space: pre-wrap; }
+details { margin: .4em 0; } summary { cursor: pointer; }
+.step { border-left: 3px solid #9ab; padding-left: .8em; margin: 1em 0; }
+.meta { color: #666; font-size: .9em; }
+.error { color: #b00; } .note { color: #a60; }
+.verdict { background: #eef8ee; padding: .6em; border-radius: 4px; margin: .6em 0; }
+.diff .add { color: #22863a; background: #f0fff4; } .diff .del { color: #b31d28; background: #ffeef0; }
+.diff .hunk { color: #6f42c1; } .diff .file { font-weight: bold; }
+.diff span { display: block; }
+</style>
+</head>
+<body>
+<h1>agent-exec {{.Mode}} run {{.RunID}}</h1>
+<table class="summary">
+<tr><td>Started</td><td>{{time .Started}}</td></tr>
+<tr><td>Duration</td><td>{{duration .Duration}}</td></tr>
+<tr><td>Cost</td><td>{{cost .CostUSD}}</td></tr>
+{{- if .FinalBranch}}<tr><td>Final branch</td><td><code>{{.FinalBranch}}</code></td></tr>{{end}}
+{{- if .Outcome}}<tr><td>Outcome</td><td class="error">{{.Outcome}}</td></tr>{{end}}
+<tr><td>Prompt</td><td><pre>{{.Prompt}}</pre></td></tr>
+</table>
+{{range .Sections}}
+<h2>{{.Title}}</h2>
+{{- range .Steps}}
+<div class="step">
+<strong>{{.Title}}</strong> <span class="meta">{{duration .Duration}}, {{cost .CostUSD}}, {{len .Tools}} tool call(s)</span>
+<details><summary>Prompt</summary><pre>{{.Prompt}}</pre></details>
+{{- range .Tools}}
+<details><summary><code>{{.Name}}</code> <span class="meta">{{duration .Duration}}</span>{{if .IsError}} <span class="error">error</span>{{end}}</summary>
+<pre>{{.Input}}</pre>{{if .Output}}<pre>{{.Output}}</pre>{{end}}
+</details>
+{{- end}}
+{{- range .Messages}}<pre>{{.}}</pre>{{end}}
+</div>
+{{- end}}
+{{- range .Candidates}}
+<details><summary>📦 <code>{{.BranchName}}</code>: {{.Subject}} <span class="meta">{{cost .CostUSD}}</span></summary>
+<pre class="diff">{{range diffLines .Diff}}<span class="{{.Class}}">{{.Text}}</span>{{end}}</pre>
+</details>
+{{- end}}
+{{- range .Verdicts}}
+<div class="verdict">🏆 <code>{{.Winner}}</code> beat <code>{{.Loser}}</code>{{if .Judge}}<pre>{{.Judge}}</pre>{{end}}</div>
+{{- end}}
+{{- range .Notes}}<p class="note">{{.}}</p>{{end}}
+{{end}}
+{{- with .Stats}}
+<h2>Tool usage</h2>
+<table class="stats">
+<tr><th></th><th>Calls</th><th>Errors</th><th>Files</th><th>Tools</th></tr>
+{{- range .Iterations}}
+<tr><td>{{.Label}}</td><td>{{template "calls" .}}</td><td>{{.Errors}}</td><td>{{len .FilesTouched}}</td><td>{{range $i, $t := .Tools}}{{if $i}}, {{end}}{{$t.Name}} {{$t.Calls}}{{end}}</td></tr>
+{{- end}}
+{{- with .Total}}
+<tr><th>{{.Label}}</th><th>{{template "calls" .}}</th><th>{{.Errors}}</th><th>{{len .FilesTouched}}</th><th>{{range $i, $t := .Tools}}{{if $i}}, {{end}}{{$t.Name}} {{$t.Calls}}{{end}}</th></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+{{define "calls"}}{{$n := 0}}{{range .Tools}}{{$n = add $n .Calls}}{{end}}{{$n}}{{end}}
+`))
+
+// WriteHTML renders the run as a self-contained HTML page
+func WriteHTML(w io.Writer, run *Run) error {
+	if err := htmlTemplate.Execute(w, run); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/LinHanLab/agent-exec/pkg/display"
+)
+
+// SummaryFile is the Markdown summary inside a run directory
+const SummaryFile = "summary.md"
+
+const (
+	maxTitleLen   = 72
+	maxJudgeLines = 15
+)
+
+// testCommand matches shell commands that run a test suite
+var testCommand = regexp.MustCompile(`(^|[\s;&|(])(go test|cargo test|(python3? -m )?pytest|npm (run )?test|yarn test|pnpm test|npx (jest|vitest)|make test|mvn test|gradle test|\./gradlew test|(bundle exec )?rspec|dotnet test|ctest|tox)\b`)
+
+// Title returns a one-line title for the run, usable as a commit subject
+func (r *Run) Title() string {
+	mode := "Run"
+	switch r.Mode {
+	case "evolve":
+		mode = "Evolve"
+	case "loop":
+		mode = "Loop"
+	}
+	prompt, _, _ := strings.Cut(strings.TrimSpace(r.Prompt), "\n")
+	if prompt == "" {
+		return mode
+	}
+	return display.Truncate(mode+": "+strings.Join(strings.Fields(prompt), " "), maxTitleLen)
+}
+
+// WriteMarkdown writes a Markdown summary of the run, suitable for a pull
+// request description
+func WriteMarkdown(w io.Writer, run *Run) error {
+	_, err := fmt.Fprintf(w, "# %s\n\n%s", run.Title(), markdownBody(run))
+	return err
+}
+
+// CommitMessage returns the run summary as a commit message: the title as
+// the subject line and the Markdown summary as the body
+func CommitMessage(run *Run) string {
+	return run.Title() + "\n\n" + markdownBody(run)
+}
+
+// markdownBody renders every part of the summary below the title
+func markdownBody(run *Run) string {
+	var b strings.Builder
+
+	writePrompt(&b, run)
+	writeOverview(&b, run)
+	writeSections(&b, run)
+	writeLineage(&b, run)
+	writeVerdicts(&b, run)
+	writeTests(&b, run)
+	writeFiles(&b, run)
+
+	return b.String()
+}
+
+func writePrompt(b *strings.Builder, run *Run) {
+	if run.Prompt == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(run.Prompt), "\n") {
+		b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+	}
+	b.WriteString("\n")
+}
+
+func writeOverview(b *strings.Builder, run *Run) {
+	if run.RunID != "" {
+		fmt.Fprintf(b, "- Run: `%s`\n", run.RunID)
+	}
+	if run.Winner != "" {
+		fmt.Fprintf(b, "- Winner: `%s`\n", run.Winner)
+	}
+	if run.FinalBranch != "" && run.FinalBranch != run.Winner {
+		fmt.Fprintf(b, "- Branch: `%s`\n", run.FinalBranch)
+	}
+	if run.Outcome != "" {
+		fmt.Fprintf(b, "- Outcome: %s\n", run.Outcome)
+	}
+	fmt.Fprintf(b, "- Duration: %s\n", textFormatter.FormatDuration(run.Duration))
+	fmt.Fprintf(b, "- Cost: $%.4f\n", run.CostUSD)
+	if run.Tokens != (Tokens{}) {
+		fmt.Fprintf(b, "- Tokens: %d input, %d output\n", run.Tokens.Input, run.Tokens.Output)
+	}
+	b.WriteString("\n")
+}
+
+// writeSections lists what happened in each round or iteration
+func writeSections(b *strings.Builder, run *Run) {
+	if len(run.Sections) == 0 {
+		return
+	}
+	if run.Mode == "evolve" {
+		b.WriteString("## Rounds\n\n")
+	} else {
+		b.WriteString("## Iterations\n\n")
+	}
+
+	for _, section := range run.Sections {
+		var cost float64
+		for _, step := range section.Steps {
+			cost += step.CostUSD
+		}
+		var parts []string
+		for _, candidate := range section.Candidates {
+			parts = append(parts, fmt.Sprintf("committed `%s` (%s)", candidate.BranchName, candidate.Subject))
+		}
+		for _, verdict := range section.Verdicts {
+			parts = append(parts, fmt.Sprintf("kept `%s` over `%s`", verdict.Winner, verdict.Loser))
+		}
+		parts = append(parts, section.Notes...)
+
+		line := fmt.Sprintf("- **%s** ($%.4f)", section.Title, cost)
+		if len(parts) > 0 {
+			line += ": " + strings.Join(parts, "; ")
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+}
+
+// writeLineage lists the winner and the candidates it was built from
+func writeLineage(b *strings.Builder, run *Run) {
+	if run.Winner == "" {
+		return
+	}
+	candidates := make(map[string]string)
+	parents := make(map[string][]string)
+	for _, section := range run.Sections {
+		for _, candidate := range section.Candidates {
+			origin := "initial implementation"
+			if candidate.Round > 0 {
+				origin = fmt.Sprintf("round %d", candidate.Round)
+			}
+			candidates[candidate.BranchName] = fmt.Sprintf("%s, %s", candidate.Subject, origin)
+			parents[candidate.BranchName] = candidate.Parents
+		}
+	}
+	if _, ok := candidates[run.Winner]; !ok {
+		return
+	}
+
+	b.WriteString("## Winner lineage\n\n")
+	seen := make(map[string]bool)
+	queue := []string{run.Winner}
+	for len(queue) > 0 {
+		branch := queue[0]
+		queue = queue[1:]
+		if seen[branch] {
+			continue
+		}
+		seen[branch] = true
+
+		line := fmt.Sprintf("- `%s`: %s", branch, candidates[branch])
+		if len(parents[branch]) > 0 {
+			line += ", from `" + strings.Join(parents[branch], "` + `") + "`"
+		}
+		b.WriteString(line + "\n")
+
+		for _, parent := range parents[branch] {
+			if _, ok := candidates[parent]; ok {
+				queue = append(queue, parent)
+			}
+		}
+	}
+	b.WriteString("\n")
+}
+
+// writeVerdicts quotes the judge's reasoning for each comparison
+func writeVerdicts(b *strings.Builder, run *Run) {
+	var verdicts strings.Builder
+	for _, section := range run.Sections {
+		for _, verdict := range section.Verdicts {
+			fmt.Fprintf(&verdicts, "**%s**: kept `%s` over `%s`\n\n", section.Title, verdict.Winner, verdict.Loser)
+			judge := strings.Split(strings.TrimSpace(verdict.Judge), "\n")
+			if len(judge) == 1 && judge[0] == "" {
+				continue
+			}
+			if len(judge) > maxJudgeLines {
+				judge = append(judge[:maxJudgeLines], "...")
+			}
+			for _, line := range judge {
+				verdicts.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+			}
+			verdicts.WriteString("\n")
+		}
+	}
+	if verdicts.Len() > 0 {
+		b.WriteString("## Verdicts\n\n")
+		b.WriteString(verdicts.String())
+	}
+}
+
+// testRun counts the runs of one test command in a section
+type testRun struct {
+	section string
+	command string
+	passed  int
+	failed  int
+}
+
+// writeTests lists the test commands Claude ran and whether they passed
+func writeTests(b *strings.Builder, run *Run) {
+	var runs []*testRun
+	index := make(map[[2]string]*testRun)
+	for _, section := range run.Sections {
+		for _, step := range section.Steps {
+			for _, call := range step.Tools {
+				if call.Command == "" || !testCommand.MatchString(call.Command) {
+					continue
+				}
+				key := [2]string{section.Title, call.Command}
+				tr, ok := index[key]
+				if !ok {
+					tr = &testRun{section: section.Title, command: call.Command}
+					index[key] = tr
+					runs = append(runs, tr)
+				}
+				if call.IsError {
+					tr.failed++
+				} else {
+					tr.passed++
+				}
+			}
+		}
+	}
+	if len(runs) == 0 {
+		return
+	}
+
+	b.WriteString("## Tests run\n\n")
+	for _, tr := range runs {
+		var results []string
+		if tr.passed > 0 {
+			results = append(results, fmt.Sprintf("%d passed", tr.passed))
+		}
+		if tr.failed > 0 {
+			results = append(results, fmt.Sprintf("%d failed", tr.failed))
+		}
+		command := strings.Join(strings.Fields(tr.command), " ")
+		fmt.Fprintf(b, "- %s: `%s` (%s)\n", tr.section, command, strings.Join(results, ", "))
+	}
+	b.WriteString("\n")
+}
+
+// fileChange counts the changed lines of one file
+type fileChange struct {
+	path    string
+	added   int
+	removed int
+}
+
+// writeFiles lists the files changed by the winner, or the files Claude
+// touched when the run has no committed winner
+func writeFiles(b *strings.Builder, run *Run) {
+	var diff string
+	for _, section := range run.Sections {
+		for _, candidate := range section.Candidates {
+			if candidate.BranchName == run.Winner {
+				diff = candidate.Diff
+			}
+		}
+	}
+
+	if diff != "" {
+		changes := diffStat(diff)
+		if len(changes) == 0 {
+			return
+		}
+		b.WriteString("## Files changed\n\n")
+		for _, change := range changes {
+			fmt.Fprintf(b, "- `%s` (+%d -%d)\n", change.path, change.added, change.removed)
+		}
+		b.WriteString("\n")
+		return
+	}
+
+	if run.Stats != nil && len(run.Stats.Total.FilesTouched) > 0 {
+		b.WriteString("## Files changed\n\n")
+		for _, path := range run.Stats.Total.FilesTouched {
+			fmt.Fprintf(b, "- `%s`\n", path)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// diffStat counts added and removed lines per file of a unified diff
+func diffStat(diff string) []*fileChange {
+	var changes []*fileChange
+	var current *fileChange
+	oldPath := ""
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			current = nil
+		case current == nil && strings.HasPrefix(line, "--- "):
+			oldPath = strings.TrimPrefix(strings.TrimPrefix(line, "--- "), "a/")
+		case current == nil && strings.HasPrefix(line, "+++ "):
+			path := strings.TrimPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+			if path == "/dev/null" {
+				path = oldPath
+			}
+			current = &fileChange{path: path}
+			changes = append(changes, current)
+		case current == nil:
+		case strings.HasPrefix(line, "+"):
+			current.added++
+		case strings.HasPrefix(line, "-"):
+			current.removed++
+		}
+	}
+	return changes
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	evs := evolveEvents()
+	result := evs[4].Data.(events.ExecutionResultData)
+	result.InputTokens, result.OutputTokens = 1200, 300
+	evs[4].Data = result
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, Build(evs)); err != nil {
+		t.Fatalf("WriteMarkdown failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Evolve: build a snake game\n",
+		"> build a snake game\n",
+		"- Winner: `impl-aaaaaa`\n",
+		"- Cost: $0.7500\n",
+		"- Tokens: 1200 input, 300 output\n",
+		"## Rounds\n",
+		"- **Initial implementation** ($0.2500): committed `impl-aaaaaa` (Add snake)\n",
+		"- **Round 1/1** ($0.5000): kept `impl-aaaaaa` over `impl-cccccc`; Challenger impl-bbbbbb failed",
+		"## Winner lineage\n\n- `impl-aaaaaa`: Add snake, initial implementation\n",
+		"**Round 1/1**: kept `impl-aaaaaa` over `impl-cccccc`\n\n> simpler and tested\n",
+		"## Tests run\n\n- Round 1/1: `go test` (1 failed)\n",
+		"## Files changed\n\n- `snake.go` (+1 -0)\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	message := CommitMessage(Build(evolveEvents()))
+
+	subject, body, _ := strings.Cut(message, "\n\n")
+	if subject != "Evolve: build a snake game" {
+		t.Errorf("subject = %q; want %q", subject, "Evolve: build a snake game")
+	}
+	if strings.HasPrefix(body, "#") || !strings.Contains(body, "## Rounds") {
+		t.Errorf("body = %q; want the summary without the title heading", body)
+	}
+}
+
+func TestRunTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		run  Run
+		want string
+	}{
+		{name: "no prompt", run: Run{Mode: "loop"}, want: "Loop"},
+		{name: "first line", run: Run{Mode: "evolve", Prompt: "add  tests\nand docs"}, want: "Evolve: add tests"},
+		{name: "long", run: Run{Prompt: strings.Repeat("x", 100)}, want: "Run: " + strings.Repeat("x", 64) + "..."},
+		{name: "long cjk", run: Run{Mode: "evolve", Prompt: strings.Repeat("写", 100)}, want: "Evolve: " + strings.Repeat("写", 61) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.run.Title(); got != tt.want {
+				t.Errorf("Title() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffStat(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/main.go b/main.go",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1,2 +1,2 @@",
+		"-old",
+		"--- not a header",
+		"+new",
+		"diff --git a/gone.txt b/gone.txt",
+		"--- a/gone.txt",
+		"+++ /dev/null",
+		"-bye",
+	}, "\n")
+
+	changes := diffStat(diff)
+	if len(changes) != 2 {
+		t.Fatalf("got %d files; want 2", len(changes))
+	}
+	if c := changes[0]; c.path != "main.go" || c.added != 1 || c.removed != 2 {
+		t.Errorf("changes[0] = %+v; want main.go +1 -2", c)
+	}
+	if c := changes[1]; c.path != "gone.txt" || c.added != 0 || c.removed != 1 {
+		t.Errorf("changes[1] = %+v; want gone.txt +0 -1", c)
+	}
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"sync"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// Recorder is an Emitter that keeps a copy of every event passing through it,
+// so a summary can be built while the run is still going
+type Recorder struct {
+	events.Emitter
+
+	mu     sync.Mutex
+	events []events.Event
+}
+
+var _ events.Emitter = (*Recorder)(nil)
+
+// NewRecorder wraps emitter with an event recorder
+func NewRecorder(emitter events.Emitter) *Recorder {
+	return &Recorder{Emitter: emitter}
+}
+
+// Emit records the event and forwards it to the wrapped emitter
+func (r *Recorder) Emit(eventType events.EventType, data interface{}) {
+	r.mu.Lock()
+	r.events = append(r.events, events.Event{Type: eventType, Timestamp: time.Now(), Data: data})
+	r.mu.Unlock()
+
+	r.Emitter.Emit(eventType, data)
+}
+
+// Events returns the events recorded so far
+func (r *Recorder) Events() []events.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]events.Event(nil), r.events...)
+}
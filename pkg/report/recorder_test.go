@@ -0,0 +1,29 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+func TestRecorder(t *testing.T) {
+	emitter := events.NewChannelEmitter(10)
+	recorder := NewRecorder(emitter)
+
+	recorder.Emit(events.EventRunPromptStarted, events.RunPromptStartedData{Prompt: "hi"})
+	recorder.Emit(events.EventWinnerSelected, events.WinnerSelectedData{Winner: "a", Loser: "b"})
+	emitter.Close()
+
+	var forwarded int
+	for range emitter.Subscribe() {
+		forwarded++
+	}
+	if forwarded != 2 {
+		t.Errorf("forwarded %d events; want 2", forwarded)
+	}
+
+	recorded := recorder.Events()
+	if len(recorded) != 2 || recorded[1].Type != events.EventWinnerSelected || recorded[1].Timestamp.IsZero() {
+		t.Errorf("recorded %+v; want both events with timestamps", recorded)
+	}
+}
@@ -0,0 +1,228 @@
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// EventsFile is the event log inside a run directory, one JSON event per line
+const EventsFile = "events.ndjson"
+
+// Load reads the event log of a run directory
+func Load(runDir string) ([]events.Event, error) {
+	path := filepath.Join(runDir, EventsFile)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+
+	var evs []events.Event
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		event, err := events.DecodeEvent(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		evs = append(evs, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event log: %w", err)
+	}
+	return evs, nil
+}
+
+// Run is a finished run rebuilt from its events
+type Run struct {
+	Mode        string // "evolve" or "loop"
+	RunID       string
+	Prompt      string // First prompt sent to Claude
+	Started     time.Time
+	Duration    time.Duration
+	CostUSD     float64
+	Tokens      Tokens
+	Winner      string // Winning candidate, before any --finish rename
+	FinalBranch string
+	Outcome     string // Set when the run did not complete normally
+	Sections    []*Section
+	Stats       *events.RunStatsData
+}
+
+// Tokens counts the tokens used by Claude
+type Tokens struct {
+	Input  int // Including cache reads and writes
+	Output int
+}
+
+// Section is a phase of the run: the initial implementation, an evolve round
+// or a loop iteration
+type Section struct {
+	Title      string
+	Steps      []*Step
+	Candidates []events.CandidateCommittedData
+	Verdicts   []events.WinnerSelectedData
+	Notes      []string
+}
+
+// Step is one Claude invocation
+type Step struct {
+	Title    string
+	Prompt   string
+	Messages []string
+	Tools    []*ToolCall
+	Duration time.Duration
+	CostUSD  float64
+}
+
+// ToolCall is a tool call paired with its result
+type ToolCall struct {
+	Name     string
+	Input    string // Pretty-printed JSON
+	Command  string // Bash only
+	Output   string
+	IsError  bool
+	Duration time.Duration
+}
+
+// Build rebuilds a run from its events
+func Build(evs []events.Event) *Run {
+	b := &builder{run: &Run{}, calls: make(map[string]*ToolCall)}
+	for _, event := range evs {
+		b.add(event)
+	}
+	// Interrupted runs never report their duration
+	if b.run.Duration == 0 && len(evs) > 0 {
+		b.run.Duration = evs[len(evs)-1].Timestamp.Sub(b.run.Started)
+	}
+	return b.run
+}
+
+// builder tracks where each event belongs while building a Run
+type builder struct {
+	run       *Run
+	nextTitle string // Title for the step started by the next prompt
+	calls     map[string]*ToolCall
+}
+
+func (b *builder) section() *Section {
+	if len(b.run.Sections) == 0 {
+		b.startSection("Run")
+	}
+	return b.run.Sections[len(b.run.Sections)-1]
+}
+
+func (b *builder) startSection(title string) {
+	b.run.Sections = append(b.run.Sections, &Section{Title: title})
+}
+
+func (b *builder) step() *Step {
+	s := b.section()
+	if len(s.Steps) == 0 {
+		s.Steps = append(s.Steps, &Step{Title: "Prompt"})
+	}
+	return s.Steps[len(s.Steps)-1]
+}
+
+func (b *builder) add(event events.Event) {
+	if b.run.Started.IsZero() {
+		b.run.Started = event.Timestamp
+	}
+
+	switch data := event.Data.(type) {
+	case events.EvolveStartedData:
+		b.run.Mode = "evolve"
+		b.run.RunID = data.RunID
+		b.startSection("Initial implementation")
+	case events.LoopStartedData:
+		b.run.Mode = "loop"
+	case events.RoundStartedData:
+		b.startSection(fmt.Sprintf("Round %d/%d", data.Round, data.Total))
+	case events.IterationStartedData:
+		b.startSection(fmt.Sprintf("Iteration %d/%d", data.Current, data.Total))
+
+	case events.ImprovementStartedData:
+		b.nextTitle = "Improvement " + data.BranchName
+	case events.CrossoverStartedData:
+		b.nextTitle = fmt.Sprintf("Crossover %s of %s and %s", data.BranchName, data.Parent1, data.Parent2)
+	case events.ComparisonStartedData:
+		b.nextTitle = fmt.Sprintf("Comparison of %s and %s", data.Branch1, data.Branch2)
+
+	case events.RunPromptStartedData:
+		if b.run.Prompt == "" {
+			b.run.Prompt = data.Prompt
+		}
+		title := b.nextTitle
+		if title == "" {
+			title = "Prompt"
+		}
+		b.nextTitle = ""
+		s := b.section()
+		s.Steps = append(s.Steps, &Step{Title: title, Prompt: data.Prompt})
+	case events.AssistantMessageData:
+		b.step().Messages = append(b.step().Messages, data.Text)
+	case events.ToolUseData:
+		input, _ := json.MarshalIndent(data.Input, "", "  ")
+		call := &ToolCall{Name: data.Name, Input: string(input)}
+		if data.Name == "Bash" {
+			call.Command, _ = data.Input["command"].(string)
+		}
+		b.step().Tools = append(b.step().Tools, call)
+		if data.ID != "" {
+			b.calls[data.ID] = call
+		}
+	case events.ToolResultData:
+		if call, ok := b.calls[data.ToolUseID]; ok {
+			call.Output = data.Content
+			call.IsError = data.IsError
+			call.Duration = data.Duration
+		}
+	case events.ExecutionResultData:
+		b.step().Duration = data.Duration
+		b.step().CostUSD = data.CostUSD
+		b.run.CostUSD += data.CostUSD
+		b.run.Tokens.Input += data.InputTokens
+		b.run.Tokens.Output += data.OutputTokens
+
+	case events.CandidateCommittedData:
+		b.section().Candidates = append(b.section().Candidates, data)
+		if data.Round == 0 {
+			b.run.Winner = data.BranchName
+		}
+	case events.WinnerSelectedData:
+		b.section().Verdicts = append(b.section().Verdicts, data)
+		b.run.Winner = data.Winner
+	case events.ChallengerFailedData:
+		b.section().Notes = append(b.section().Notes, fmt.Sprintf("Challenger %s failed (%s): %s", data.BranchName, data.Reason, data.Error))
+	case events.IterationFailedData:
+		msg := "unknown error"
+		if data.Error != nil {
+			msg = data.Error.Error()
+		}
+		b.section().Notes = append(b.section().Notes, "Iteration failed: "+msg)
+	case events.EvolveFinishedData:
+		b.section().Notes = append(b.section().Notes, fmt.Sprintf("Finished with %s: %s on %s", data.Mode, data.Winner, data.Branch))
+
+	case events.EvolveCompletedData:
+		b.run.FinalBranch = data.FinalBranch
+		b.run.Duration = data.TotalDuration
+	case events.LoopCompletedData:
+		b.run.Duration = data.TotalDuration
+	case events.EvolveInterruptedData:
+		b.run.Outcome = "interrupted"
+	case events.LoopInterruptedData:
+		b.run.Outcome = "interrupted"
+	case events.RunStatsData:
+		b.run.Stats = &data
+	}
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// evolveEvents is a small evolve run: an initial implementation and one round
+func evolveEvents() []events.Event {
+	start := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	data := []struct {
+		eventType events.EventType
+		data      interface{}
+	}{
+		{events.EventEvolveStarted, events.EvolveStartedData{TotalIterations: 1, RunID: "run-1"}},
+		{events.EventRunPromptStarted, events.RunPromptStartedData{Prompt: "build a snake game"}},
+		{events.EventClaudeToolUse, events.ToolUseData{ID: "t1", Name: "Write", Input: map[string]interface{}{"file_path": "snake.go"}}},
+		{events.EventClaudeToolResult, events.ToolResultData{ToolUseID: "t1", Name: "Write", Content: "File created", Duration: time.Second}},
+		{events.EventClaudeExecutionResult, events.ExecutionResultData{Duration: 3 * time.Second, CostUSD: 0.25}},
+		{events.EventCandidateCommitted, events.CandidateCommittedData{BranchName: "impl-aaaaaa", Subject: "Add snake", Diff: "+++ b/snake.go\n+package main\n", CostUSD: 0.25}},
+		{events.EventRoundStarted, events.RoundStartedData{Round: 1, Total: 1}},
+		{events.EventImprovementStarted, events.ImprovementStartedData{BranchName: "impl-bbbbbb", Prompt: "improve"}},
+		{events.EventRunPromptStarted, events.RunPromptStartedData{Prompt: "improve"}},
+		{events.EventClaudeToolUse, events.ToolUseData{ID: "t2", Name: "Bash", Input: map[string]interface{}{"command": "go test"}}},
+		{events.EventClaudeToolResult, events.ToolResultData{ToolUseID: "t2", Name: "Bash", Content: "FAIL <snake>", IsError: true}},
+		{events.EventClaudeExecutionResult, events.ExecutionResultData{Duration: 2 * time.Second, CostUSD: 0.5}},
+		{events.EventChallengerFailed, events.ChallengerFailedData{Round: 1, BranchName: "impl-bbbbbb", Reason: "max_turns", Error: "ran out of turns"}},
+		{events.EventWinnerSelected, events.WinnerSelectedData{Winner: "impl-aaaaaa", Loser: "impl-cccccc", Judge: "simpler and tested"}},
+		{events.EventIterationFailed, events.IterationFailedData{Current: 1, Total: 1, Error: errors.New("boom")}},
+		{events.EventEvolveCompleted, events.EvolveCompletedData{FinalBranch: "impl-aaaaaa", TotalRounds: 1, TotalDuration: time.Minute}},
+	}
+
+	var evs []events.Event
+	for i, d := range data {
+		evs = append(evs, events.Event{Type: d.eventType, Timestamp: start.Add(time.Duration(i) * time.Second), Data: d.data})
+	}
+	return evs
+}
+
+func TestBuild(t *testing.T) {
+	run := Build(evolveEvents())
+
+	if run.Mode != "evolve" || run.RunID != "run-1" || run.Prompt != "build a snake game" {
+		t.Errorf("run = %s %s %q; want evolve run-1 with the first prompt", run.Mode, run.RunID, run.Prompt)
+	}
+	if run.CostUSD != 0.75 {
+		t.Errorf("CostUSD = %v; want 0.75", run.CostUSD)
+	}
+	if run.Duration != time.Minute || run.FinalBranch != "impl-aaaaaa" {
+		t.Errorf("Duration, FinalBranch = %v, %s; want 1m0s, impl-aaaaaa", run.Duration, run.FinalBranch)
+	}
+
+	if len(run.Sections) != 2 {
+		t.Fatalf("got %d sections; want 2", len(run.Sections))
+	}
+	initial, round := run.Sections[0], run.Sections[1]
+	if initial.Title != "Initial implementation" || len(initial.Candidates) != 1 {
+		t.Errorf("initial section = %+v; want one candidate", initial)
+	}
+	if round.Title != "Round 1/1" || len(round.Steps) != 1 || round.Steps[0].Title != "Improvement impl-bbbbbb" {
+		t.Fatalf("round section = %+v; want one improvement step", round)
+	}
+
+	bash := round.Steps[0].Tools[0]
+	if bash.Name != "Bash" || bash.Output != "FAIL <snake>" || !bash.IsError {
+		t.Errorf("tool call = %+v; want the failed Bash call paired with its result", bash)
+	}
+	if len(round.Verdicts) != 1 || len(round.Notes) != 2 {
+		t.Errorf("round verdicts, notes = %d, %d; want 1, 2", len(round.Verdicts), len(round.Notes))
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, Build(evolveEvents())); err != nil {
+		t.Fatalf("WriteHTML() error: %v", err)
+	}
+
+	html := buf.String()
+	for _, want := range []string{
+		"agent-exec evolve run run-1",
+		"<h2>Round 1/1</h2>",
+		`<span class="add">&#43;package main</span>`,
+		"simpler and tested",
+		"FAIL &lt;snake&gt;",
+		"$0.7500",
+		"<details>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	for _, event := range evolveEvents() {
+		line, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(line)
+		buf.WriteString("\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, EventsFile), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	evs, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(evs) != len(evolveEvents()) {
+		t.Fatalf("got %d events; want %d", len(evs), len(evolveEvents()))
+	}
+	if data, ok := evs[len(evs)-1].Data.(events.EvolveCompletedData); !ok || data.FinalBranch != "impl-aaaaaa" {
+		t.Errorf("last event data = %+v; want the evolve completion", evs[len(evs)-1].Data)
+	}
+
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Error("Expected an error for a directory without an event log")
+	}
+}
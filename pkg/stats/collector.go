@@ -0,0 +1,154 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+// fileInputKeys maps tools that modify files to the input holding the path
+var fileInputKeys = map[string]string{
+	"Edit":         "file_path",
+	"MultiEdit":    "file_path",
+	"Write":        "file_path",
+	"NotebookEdit": "notebook_path",
+}
+
+// Collector is an Emitter that tallies tool usage from the events passing
+// through it and emits an EventRunStats summary when a loop or evolve run
+// ends, whether it completed, failed or was interrupted
+type Collector struct {
+	events.Emitter
+
+	iterations []*tally
+	total      *tally
+	seen       bool // Any event passed through, so there is a run to summarize
+	summarized bool
+}
+
+var _ events.Emitter = (*Collector)(nil)
+
+// NewCollector wraps emitter with a statistics collector
+func NewCollector(emitter events.Emitter) *Collector {
+	return &Collector{Emitter: emitter, total: newTally("Total")}
+}
+
+// Emit records the event and forwards it to the wrapped emitter
+func (c *Collector) Emit(eventType events.EventType, data interface{}) {
+	c.seen = true
+	c.record(eventType, data)
+	c.Emitter.Emit(eventType, data)
+
+	// Evolve reports its cleanup on every exit path, after completing or not
+	switch eventType {
+	case events.EventLoopCompleted, events.EventLoopInterrupted, events.EventEvolveCompleted, events.EventEvolveCleanup:
+		c.Summarize()
+	}
+}
+
+// Summarize emits the EventRunStats summary, once per run. Loop and evolve
+// runs trigger it through their final events; callers also run it when the
+// run returns to cover the rest, such as a single prompt run or one that
+// failed. It emits nothing when no event passed through.
+func (c *Collector) Summarize() {
+	if c.summarized || !c.seen {
+		return
+	}
+	c.summarized = true
+	c.Emitter.Emit(events.EventRunStats, c.Stats())
+}
+
+// record updates the tallies for one event
+func (c *Collector) record(eventType events.EventType, data interface{}) {
+	switch d := data.(type) {
+	case events.IterationStartedData:
+		c.iterations = append(c.iterations, newTally(fmt.Sprintf("Iteration %d", d.Current)))
+	case events.RoundStartedData:
+		c.iterations = append(c.iterations, newTally(fmt.Sprintf("Round %d", d.Round)))
+	case events.ToolUseData:
+		for _, t := range c.current() {
+			t.addCall(d)
+		}
+	case events.ToolResultData:
+		if d.IsError {
+			for _, t := range c.current() {
+				t.addError(d.Name)
+			}
+		}
+	}
+}
+
+// current returns the tallies an event counts towards
+func (c *Collector) current() []*tally {
+	if len(c.iterations) == 0 {
+		return []*tally{c.total}
+	}
+	return []*tally{c.iterations[len(c.iterations)-1], c.total}
+}
+
+// Stats returns the statistics collected so far
+func (c *Collector) Stats() events.RunStatsData {
+	var data events.RunStatsData
+	for _, t := range c.iterations {
+		data.Iterations = append(data.Iterations, t.stats())
+	}
+	data.Total = c.total.stats()
+	return data
+}
+
+// tally accumulates the tool usage of one iteration or a whole run
+type tally struct {
+	label  string
+	tools  map[string]*events.ToolCount
+	order  []string // Tool names in order of first use
+	errors int
+	files  map[string]bool
+}
+
+func newTally(label string) *tally {
+	return &tally{
+		label: label,
+		tools: make(map[string]*events.ToolCount),
+		files: make(map[string]bool),
+	}
+}
+
+// tool returns the counter for name, creating it on first use
+func (t *tally) tool(name string) *events.ToolCount {
+	count, ok := t.tools[name]
+	if !ok {
+		count = &events.ToolCount{Name: name}
+		t.tools[name] = count
+		t.order = append(t.order, name)
+	}
+	return count
+}
+
+func (t *tally) addCall(data events.ToolUseData) {
+	t.tool(data.Name).Calls++
+	if key, ok := fileInputKeys[data.Name]; ok {
+		if path, ok := data.Input[key].(string); ok && path != "" {
+			t.files[path] = true
+		}
+	}
+}
+
+func (t *tally) addError(name string) {
+	t.errors++
+	if name != "" {
+		t.tool(name).Errors++
+	}
+}
+
+func (t *tally) stats() events.ToolStats {
+	s := events.ToolStats{Label: t.label, Errors: t.errors}
+	for _, name := range t.order {
+		s.Tools = append(s.Tools, *t.tools[name])
+	}
+	for path := range t.files {
+		s.FilesTouched = append(s.FilesTouched, path)
+	}
+	sort.Strings(s.FilesTouched)
+	return s
+}
@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/LinHanLab/agent-exec/pkg/events"
+)
+
+func TestCollector(t *testing.T) {
+	inner := events.NewChannelEmitter(100)
+	collector := NewCollector(inner)
+
+	collector.Emit(events.EventIterationStarted, events.IterationStartedData{Current: 1, Total: 2})
+	collector.Emit(events.EventClaudeToolUse, events.ToolUseData{Name: "Read", Input: map[string]interface{}{"file_path": "a.go"}})
+	collector.Emit(events.EventClaudeToolUse, events.ToolUseData{Name: "Edit", Input: map[string]interface{}{"file_path": "a.go"}})
+	collector.Emit(events.EventIterationStarted, events.IterationStartedData{Current: 2, Total: 2})
+	collector.Emit(events.EventClaudeToolUse, events.ToolUseData{Name: "Bash", Input: map[string]interface{}{"command": "go test"}})
+	collector.Emit(events.EventClaudeToolResult, events.ToolResultData{Name: "Bash", IsError: true})
+	collector.Emit(events.EventClaudeToolUse, events.ToolUseData{Name: "Write", Input: map[string]interface{}{"file_path": "b.go"}})
+	collector.Emit(events.EventLoopCompleted, events.LoopCompletedData{TotalIterations: 2})
+	inner.Close()
+
+	var got []events.Event
+	for event := range inner.Subscribe() {
+		got = append(got, event)
+	}
+
+	if len(got) != 9 {
+		t.Fatalf("got %d events; want 9 (8 forwarded plus the summary)", len(got))
+	}
+	last := got[len(got)-1]
+	if last.Type != events.EventRunStats {
+		t.Fatalf("last event = %s; want %s", last.Type, events.EventRunStats)
+	}
+
+	want := events.RunStatsData{
+		Iterations: []events.ToolStats{
+			{
+				Label:        "Iteration 1",
+				Tools:        []events.ToolCount{{Name: "Read", Calls: 1}, {Name: "Edit", Calls: 1}},
+				FilesTouched: []string{"a.go"},
+			},
+			{
+				Label:        "Iteration 2",
+				Tools:        []events.ToolCount{{Name: "Bash", Calls: 1, Errors: 1}, {Name: "Write", Calls: 1}},
+				Errors:       1,
+				FilesTouched: []string{"b.go"},
+			},
+		},
+		Total: events.ToolStats{
+			Label: "Total",
+			Tools: []events.ToolCount{
+				{Name: "Read", Calls: 1},
+				{Name: "Edit", Calls: 1},
+				{Name: "Bash", Calls: 1, Errors: 1},
+				{Name: "Write", Calls: 1},
+			},
+			Errors:       1,
+			FilesTouched: []string{"a.go", "b.go"},
+		},
+	}
+	if data := last.Data.(events.RunStatsData); !reflect.DeepEqual(data, want) {
+		t.Errorf("stats = %+v; want %+v", data, want)
+	}
+}
+
+func TestCollector_EvolveRounds(t *testing.T) {
+	collector := NewCollector(events.NewNullEmitter())
+
+	collector.Emit(events.EventRoundStarted, events.RoundStartedData{Round: 1, Total: 1})
+	collector.Emit(events.EventClaudeToolUse, events.ToolUseData{Name: "Bash"})
+
+	stats := collector.Stats()
+	if len(stats.Iterations) != 1 || stats.Iterations[0].Label != "Round 1" {
+		t.Fatalf("iterations = %+v; want one labelled Round 1", stats.Iterations)
+	}
+	if len(stats.Total.Tools) != 1 || stats.Total.Tools[0].Calls != 1 {
+		t.Errorf("total tools = %+v; want one Bash call", stats.Total.Tools)
+	}
+}
+
+func TestCollector_SummaryOnEveryExit(t *testing.T) {
+	tests := []struct {
+		name      string
+		events    []events.EventType
+		summarize bool // The caller summarizes after the run returns
+		want      int  // EventRunStats emitted
+	}{
+		{name: "loop completed", events: []events.EventType{events.EventLoopStarted, events.EventLoopCompleted}, want: 1},
+		{name: "loop interrupted", events: []events.EventType{events.EventLoopStarted, events.EventLoopInterrupted}, summarize: true, want: 1},
+		{name: "evolve completed", events: []events.EventType{events.EventEvolveStarted, events.EventEvolveCompleted, events.EventEvolveCleanup}, summarize: true, want: 1},
+		{name: "evolve failed", events: []events.EventType{events.EventEvolveStarted, events.EventEvolveCleanup}, want: 1},
+		{name: "single run", events: []events.EventType{events.EventRunPromptStarted, events.EventClaudeExecutionResult}, summarize: true, want: 1},
+		{name: "single run failed", events: []events.EventType{events.EventRunPromptStarted}, summarize: true, want: 1},
+		{name: "nothing ran", summarize: true, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := events.NewChannelEmitter(100)
+			collector := NewCollector(inner)
+			for _, eventType := range tt.events {
+				collector.Emit(eventType, nil)
+			}
+			if tt.summarize {
+				collector.Summarize()
+			}
+			inner.Close()
+
+			got := 0
+			for event := range inner.Subscribe() {
+				if event.Type == events.EventRunStats {
+					got++
+				}
+			}
+			if got != tt.want {
+				t.Errorf("got %d summaries; want %d", got, tt.want)
+			}
+		})
+	}
+}